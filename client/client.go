@@ -0,0 +1,129 @@
+// Package client is the Go SDK to send emails through the emailer: build the
+// messages, validate them with the rules of the service and publish them to
+// Kafka or the HTTP API.
+package client
+
+import (
+	// Go Internal Packages
+	"context"
+	"fmt"
+	"time"
+
+	// Local Packages
+	errors "github.com/satya-ajayy/Emailer/errors"
+	models "github.com/satya-ajayy/Emailer/models"
+	schemas "github.com/satya-ajayy/Emailer/templates/schemas"
+	mailaddr "github.com/satya-ajayy/Emailer/utils/mailaddr"
+)
+
+// Publisher publishes the messages to the emailer.
+type Publisher interface {
+	Publish(ctx context.Context, messages []Message) error
+}
+
+// Options mirror the service config the messages are validated against.
+type Options struct {
+	// DefaultTemplate is the service's templates.default, used to validate
+	// the messages without a template.
+	DefaultTemplate string
+	// Addresses is the service's addresses policy, the MX lookups excepted.
+	Addresses models.Addresses
+	// MaxDelay is the service's schedule.max_delay.
+	MaxDelay time.Duration
+}
+
+// DefaultOptions are the options of the service's default config.
+var DefaultOptions = Options{DefaultTemplate: "problems", MaxDelay: 15 * time.Minute}
+
+// Client validates and publishes the messages.
+type Client struct {
+	publisher Publisher
+	options   Options
+	schemas   schemas.Schemas
+	addresses *mailaddr.Validator
+}
+
+// New creates a new Client publishing through the publisher. The messages are
+// validated against the schemas of the default templates, the ones of custom
+// templates are only checked by the service.
+func New(publisher Publisher, opts Options) (*Client, error) {
+	defaults, err := schemas.Default()
+	if err != nil {
+		return nil, fmt.Errorf("error reading template schemas: %v", err)
+	}
+
+	opts.Addresses.CheckMX = false
+	return &Client{
+		publisher: publisher,
+		options:   opts,
+		schemas:   defaults,
+		addresses: mailaddr.NewValidator(opts.Addresses, nil),
+	}, nil
+}
+
+// Send builds, validates and publishes the messages of the email, returning
+// their message IDs. Nothing is published when any message is invalid.
+func (c *Client) Send(ctx context.Context, email *Builder) ([]string, error) {
+	messages, err := email.Build()
+	if err != nil {
+		return nil, err
+	}
+	return c.Publish(ctx, messages...)
+}
+
+// Publish validates and publishes the messages, returning their message IDs.
+// Nothing is published when any message is invalid, the validation errors of
+// several messages are reported per message, e.g. messages.2.user.mail_id.
+func (c *Client) Publish(ctx context.Context, messages ...Message) ([]string, error) {
+	if len(messages) == 0 {
+		return nil, errors.EmptyParamErr("messages")
+	}
+
+	ve := errors.ValidationErrs()
+	for i, message := range messages {
+		err := c.Validate(ctx, message)
+		if err != nil && len(messages) == 1 {
+			return nil, err
+		}
+		if err != nil {
+			ve.AddErr(fmt.Sprintf("messages.%d", i), err)
+		}
+	}
+	if ve.Len() > 0 {
+		return nil, errors.ValidationFailedErr(ve.Err())
+	}
+
+	if err := c.publisher.Publish(ctx, messages); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(messages))
+	for i, message := range messages {
+		ids[i] = message.ID
+	}
+	return ids, nil
+}
+
+// Validate checks the message against the schema of its template, the
+// addresses policy and the attachments and schedule rules of the service.
+func (c *Client) Validate(ctx context.Context, message Message) error {
+	if message.ID == "" {
+		return errors.EmptyParamErr("id")
+	}
+
+	value, err := message.Value()
+	if err != nil {
+		return errors.InvalidBodyErr(err)
+	}
+	template := message.Email.Template
+	if template == "" {
+		template = c.options.DefaultTemplate
+	}
+	if err = c.schemas.Validate(template, value); err != nil {
+		return err
+	}
+
+	if _, err = c.addresses.Normalize(ctx, "user.mail_id", message.Email.User.MailID); err != nil {
+		return err
+	}
+	return message.Email.Validate(time.Now(), c.options.MaxDelay)
+}
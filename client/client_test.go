@@ -0,0 +1,143 @@
+package client
+
+import (
+	// Go Internal Packages
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	// Local Packages
+	errors "github.com/satya-ajayy/Emailer/errors"
+	models "github.com/satya-ajayy/Emailer/models"
+)
+
+// published records the messages of every Publish call.
+type published struct {
+	calls [][]Message
+}
+
+func (p *published) Publish(_ context.Context, messages []Message) error {
+	p.calls = append(p.calls, messages)
+	return nil
+}
+
+// fields returns the fields of the validation errors of err.
+func fields(t *testing.T, err error) []string {
+	t.Helper()
+	var ve errors.ValidationErrors
+	if !errors.As(err, &ve) {
+		t.Fatalf("error %v has no field errors", err)
+	}
+	fields := make([]string, len(ve))
+	for i, fe := range ve {
+		fields[i] = fe.Field
+	}
+	return fields
+}
+
+func TestValidate(t *testing.T) {
+	c, err := New(&published{}, DefaultOptions)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	problem := models.Problem{ID: "1", Name: "Two Sum", Link: "https://example.com/problems/1"}
+	message := func(edit func(*models.UserLinks)) Message {
+		email := models.UserLinks{User: models.UserData{UserName: "Ada", MailID: "ada@example.com"},
+			Problems: []models.Problem{problem}}
+		if edit != nil {
+			edit(&email)
+		}
+		return Message{ID: "weekly-42", Email: email}
+	}
+	at := func(d time.Duration) *time.Time {
+		t := time.Now().Add(d)
+		return &t
+	}
+
+	tests := []struct {
+		name    string
+		message Message
+		fields  []string
+	}{
+		{name: "valid", message: message(nil)},
+		{name: "schema of the default template", message: message(func(e *models.UserLinks) { e.Problems = nil }),
+			fields: []string{"problems"}},
+		{name: "no schema for custom templates", message: message(func(e *models.UserLinks) {
+			e.Template, e.Problems = "password-reset", nil
+		})},
+		{name: "invalid address", message: message(func(e *models.UserLinks) { e.User.MailID = "ada@" }),
+			fields: []string{"user.mail_id"}},
+		{name: "disposable address", message: message(func(e *models.UserLinks) { e.User.MailID = "ada@mailinator.com" }),
+			fields: []string{"user.mail_id"}},
+		{name: "attachment", message: message(func(e *models.UserLinks) {
+			e.Attachments = []models.Attachment{{Filename: "report.pdf", ContentType: "application/pdf", Content: []byte("%PDF")}}
+		})},
+		{name: "invalid attachment", message: message(func(e *models.UserLinks) {
+			e.Attachments = []models.Attachment{{Filename: "../report.pdf", ContentType: "application/pdf; charset"}}
+		}), fields: []string{"attachments.0.filename", "attachments.0.content_type", "attachments.0.content"}},
+		{name: "attachments too large", message: message(func(e *models.UserLinks) {
+			e.Attachments = []models.Attachment{
+				{Filename: "a.bin", Content: bytes.Repeat([]byte("a"), models.MaxAttachmentsSize/2)},
+				{Filename: "b.bin", Content: bytes.Repeat([]byte("b"), models.MaxAttachmentsSize/2+1)},
+			}
+		}), fields: []string{"attachments"}},
+		{name: "send at within the max delay", message: message(func(e *models.UserLinks) { e.SendAt = at(14 * time.Minute) })},
+		{name: "send at in the past", message: message(func(e *models.UserLinks) { e.SendAt = at(-time.Hour) })},
+		{name: "send at over the max delay", message: message(func(e *models.UserLinks) { e.SendAt = at(16 * time.Minute) }),
+			fields: []string{"send_at"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.Validate(context.Background(), tt.message)
+			if tt.fields == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if got := fields(t, err); !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("fields = %v, want %v", got, tt.fields)
+			}
+		})
+	}
+
+	if err = c.Validate(context.Background(), Message{Email: message(nil).Email}); errors.KindOf(err) != errors.Invalid {
+		t.Errorf("Validate() without ID error = %v, want an invalid error", err)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	publisher := &published{}
+	c, err := New(publisher, DefaultOptions)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	email := NewEmail("problems").To("Ada", "ada@example.com").To("Alan", "alan@mailinator.com").
+		Problems(models.Problem{ID: "1", Name: "Two Sum", Link: "https://example.com/problems/1"}).
+		IdempotencyKey("weekly-42")
+
+	// nothing is published when any message is invalid
+	_, err = c.Send(context.Background(), email)
+	if got := fields(t, err); !reflect.DeepEqual(got, []string{"messages.1.user.mail_id"}) {
+		t.Errorf("fields = %v, want messages.1.user.mail_id", got)
+	}
+	if len(publisher.calls) != 0 {
+		t.Fatalf("published %d times, want none", len(publisher.calls))
+	}
+
+	ids, err := c.Send(context.Background(), NewEmail("problems").To("Ada", "ada@example.com").
+		To("Grace", "grace@example.com").
+		Problems(models.Problem{ID: "1", Name: "Two Sum", Link: "https://example.com/problems/1"}).
+		IdempotencyKey("weekly-42"))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if want := []string{"weekly-42/0", "weekly-42/1"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("IDs = %v, want %v", ids, want)
+	}
+	if len(publisher.calls) != 1 || len(publisher.calls[0]) != 2 {
+		t.Errorf("published %v, want the two messages at once", publisher.calls)
+	}
+}
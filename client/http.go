@@ -0,0 +1,119 @@
+package client
+
+import (
+	// Go Internal Packages
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	// Local Packages
+	errors "github.com/satya-ajayy/Emailer/errors"
+)
+
+// HTTPPublisher sends the messages to the HTTP API of the emailer.
+type HTTPPublisher struct {
+	client  *http.Client
+	baseURL string
+	token   string
+}
+
+// NewHTTPPublisher creates a new HTTPPublisher for the emailer served at
+// baseURL, e.g. http://emailer:2529/emailer. A nil client is
+// http.DefaultClient.
+func NewHTTPPublisher(baseURL string, client *http.Client) *HTTPPublisher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPPublisher{client: client, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// WithToken authenticates the requests with the service's auth.api_token.
+func (p *HTTPPublisher) WithToken(token string) *HTTPPublisher {
+	p.token = token
+	return p
+}
+
+// errorResponse is the body of the API error responses.
+type errorResponse struct {
+	Message          string                  `json:"message"`
+	ValidationErrors errors.ValidationErrors `json:"validation_errors"`
+}
+
+// batchRequest is the body of the batch endpoint, with the message IDs as the
+// idempotency keys of its emails.
+type batchRequest struct {
+	Emails          []json.RawMessage `json:"emails"`
+	IdempotencyKeys []string          `json:"idempotency_keys"`
+}
+
+// Publish posts the messages in a single batch request, so they're all
+// accepted or none of them, up to the service's ingest.max_batch. Their IDs are
+// the idempotency keys: publishing them again after a failure doesn't queue the
+// ones the service still knows as accepted (ingest.status_ttl), and the
+// consumers skip the ones they've sent (idempotency.ttl). Both are kept in
+// memory, a restart forgets them.
+func (p *HTTPPublisher) Publish(ctx context.Context, messages []Message) error {
+	batch := batchRequest{
+		Emails:          make([]json.RawMessage, len(messages)),
+		IdempotencyKeys: make([]string, len(messages)),
+	}
+	for i, message := range messages {
+		value, err := message.Value()
+		if err != nil {
+			return fmt.Errorf("error encoding message %s: %w", message.ID, err)
+		}
+		batch.Emails[i], batch.IdempotencyKeys[i] = value, message.ID
+	}
+	payload, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/v1/emails/batch", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusAccepted {
+		return nil
+	}
+
+	var body errorResponse
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Message == "" {
+		body.Message = resp.Status
+	}
+	if len(body.ValidationErrors) > 0 {
+		return errors.E(statusKind(resp.StatusCode), body.Message, body.ValidationErrors)
+	}
+	return errors.E(statusKind(resp.StatusCode), body.Message)
+}
+
+// statusKind maps the HTTP status of an error response back to its kind.
+func statusKind(status int) errors.Kind {
+	switch status {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge:
+		return errors.Invalid
+	case http.StatusNotFound:
+		return errors.NotFound
+	case http.StatusUnauthorized:
+		return errors.Unauthorized
+	case http.StatusForbidden:
+		return errors.Forbidden
+	case http.StatusServiceUnavailable:
+		return errors.Unavailable
+	default:
+		return errors.Internal
+	}
+}
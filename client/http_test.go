@@ -0,0 +1,114 @@
+package client
+
+import (
+	// Go Internal Packages
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	// Local Packages
+	errors "github.com/satya-ajayy/Emailer/errors"
+	models "github.com/satya-ajayy/Emailer/models"
+)
+
+func TestHTTPPublish(t *testing.T) {
+	var got struct {
+		path, auth string
+		batch      batchRequest
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.path, got.auth = r.URL.Path, r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got.batch); err != nil {
+			t.Errorf("decode batch: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	messages := []Message{
+		{ID: "weekly-42/0", Email: models.UserLinks{User: models.UserData{UserName: "Ada", MailID: "ada@example.com"}}},
+		{ID: "weekly-42/1", Email: models.UserLinks{User: models.UserData{UserName: "Alan", MailID: "alan@example.com"}}},
+	}
+	err := NewHTTPPublisher(server.URL+"/emailer/", nil).WithToken("secret").Publish(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	if got.path != "/emailer/v1/emails/batch" {
+		t.Errorf("path = %s, want /emailer/v1/emails/batch", got.path)
+	}
+	if got.auth != "Bearer secret" {
+		t.Errorf("authorization = %q, want Bearer secret", got.auth)
+	}
+	// the message IDs are the idempotency keys of the batch
+	if want := []string{"weekly-42/0", "weekly-42/1"}; !reflect.DeepEqual(got.batch.IdempotencyKeys, want) {
+		t.Errorf("idempotency keys = %v, want %v", got.batch.IdempotencyKeys, want)
+	}
+	if len(got.batch.Emails) != 2 {
+		t.Fatalf("posted %d emails, want 2", len(got.batch.Emails))
+	}
+	var email models.UserLinks
+	if err = json.Unmarshal(got.batch.Emails[1], &email); err != nil || email.User.MailID != "alan@example.com" {
+		t.Errorf("second email = %s, want the one of alan@example.com", got.batch.Emails[1])
+	}
+}
+
+func TestHTTPPublishErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		kind    errors.Kind
+		message string
+		fields  []string
+	}{
+		{
+			name:    "validation errors",
+			status:  http.StatusBadRequest,
+			body:    `{"message":"validation failed","validation_errors":[{"field":"emails.1.user.mail_id","error":"is a role account"}]}`,
+			kind:    errors.Invalid,
+			message: "validation failed",
+			fields:  []string{"emails.1.user.mail_id"},
+		},
+		{
+			name:    "too large",
+			status:  http.StatusRequestEntityTooLarge,
+			body:    `{"message":"request body cannot exceed 10 bytes"}`,
+			kind:    errors.Invalid,
+			message: "request body cannot exceed 10 bytes",
+		},
+		{name: "unauthorized", status: http.StatusUnauthorized, body: `{"message":"unauthorized"}`,
+			kind: errors.Unauthorized, message: "unauthorized"},
+		{name: "queue full", status: http.StatusServiceUnavailable, body: `{"message":"queue is full"}`,
+			kind: errors.Unavailable, message: "queue is full"},
+		{name: "not JSON", status: http.StatusBadGateway, body: `<html>bad gateway</html>`,
+			kind: errors.Internal, message: "502 Bad Gateway"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			err := NewHTTPPublisher(server.URL, nil).Publish(context.Background(), []Message{{ID: "weekly-42"}})
+			if errors.KindOf(err) != tt.kind || errors.Message(err) != tt.message {
+				t.Fatalf("Publish() error = %v (%v), want %v %q", err, errors.KindOf(err), tt.kind, tt.message)
+			}
+
+			var ve errors.ValidationErrors
+			errors.As(err, &ve)
+			var fields []string
+			for _, fe := range ve {
+				fields = append(fields, fe.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("fields = %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+}
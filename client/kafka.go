@@ -0,0 +1,54 @@
+package client
+
+import (
+	// Go Internal Packages
+	"context"
+	"fmt"
+
+	// Local Packages
+	models "github.com/satya-ajayy/Emailer/models"
+
+	// External Packages
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel"
+)
+
+// DefaultTopic is the topic the emailer consumes by default.
+const DefaultTopic = "emails-to-send"
+
+// KafkaPublisher produces the messages to the topic consumed by the emailer.
+type KafkaPublisher struct {
+	client *kgo.Client
+	topic  string
+}
+
+// NewKafkaPublisher creates a new KafkaPublisher producing with the franz-go
+// client, which stays owned by the caller.
+func NewKafkaPublisher(client *kgo.Client, topic string) *KafkaPublisher {
+	return &KafkaPublisher{client: client, topic: topic}
+}
+
+// Publish produces the messages, keyed by their ID, and waits for the brokers
+// to acknowledge them. The trace context of ctx is sent along in the headers.
+func (p *KafkaPublisher) Publish(ctx context.Context, messages []Message) error {
+	records := make([]*kgo.Record, len(messages))
+	for i, message := range messages {
+		value, err := message.Value()
+		if err != nil {
+			return err
+		}
+
+		record := models.Record{Key: []byte(message.ID), Value: value, Headers: message.Headers()}
+		otel.GetTextMapPropagator().Inject(ctx, models.HeaderCarrier{Record: &record})
+		headers := make([]kgo.RecordHeader, len(record.Headers))
+		for j, h := range record.Headers {
+			headers[j] = kgo.RecordHeader{Key: h.Key, Value: h.Value}
+		}
+		records[i] = &kgo.Record{Topic: p.topic, Key: record.Key, Value: record.Value, Headers: headers}
+	}
+
+	if err := p.client.ProduceSync(ctx, records...).FirstErr(); err != nil {
+		return fmt.Errorf("error producing messages: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package client
+
+import (
+	// Go Internal Packages
+	"encoding/json"
+	"fmt"
+	"time"
+
+	// Local Packages
+	errors "github.com/satya-ajayy/Emailer/errors"
+	models "github.com/satya-ajayy/Emailer/models"
+
+	// External Packages
+	"github.com/google/uuid"
+)
+
+// Message is the email request of one recipient, as published to the emailer.
+type Message struct {
+	// ID is the message ID, sent as the idempotency key so a message
+	// published twice is only sent once while the service remembers it.
+	ID    string
+	Email models.UserLinks
+}
+
+// Value returns the message encoded as the Kafka record values.
+func (m Message) Value() ([]byte, error) {
+	return json.Marshal(m.Email)
+}
+
+// Headers returns the record headers of the message.
+func (m Message) Headers() []models.RecordHeader {
+	return []models.RecordHeader{
+		{Key: models.HeaderContentType, Value: []byte("application/json")},
+		{Key: models.HeaderSchemaVersion, Value: []byte("1")},
+		{Key: models.HeaderIdempotencyKey, Value: []byte(m.ID)},
+	}
+}
+
+// Builder builds the messages of an email sent to one or more recipients,
+// e.g.
+//
+//	client.NewEmail("problems").
+//		To("Ada", "ada@example.com").
+//		Problems(models.Problem{ID: "1", Name: "Two Sum", Link: "https://example.com/1"}).
+//		Attach("report.pdf", "application/pdf", report).
+//		IdempotencyKey("weekly-2026-42")
+type Builder struct {
+	template    string
+	recipients  []models.UserData
+	problems    []models.Problem
+	data        map[string]any
+	attachments []models.Attachment
+	sendAt      *time.Time
+	key         string
+}
+
+// NewEmail starts an email rendered with the template, the service's default
+// template when empty.
+func NewEmail(template string) *Builder {
+	return &Builder{template: template}
+}
+
+// To adds a recipient.
+func (b *Builder) To(name, address string) *Builder {
+	return b.ToUser(models.UserData{UserName: name, MailID: address})
+}
+
+// ToUser adds a recipient with their locale and time zone.
+func (b *Builder) ToUser(user models.UserData) *Builder {
+	b.recipients = append(b.recipients, user)
+	return b
+}
+
+// Problems adds the problems listed by the template.
+func (b *Builder) Problems(problems ...models.Problem) *Builder {
+	b.problems = append(b.problems, problems...)
+	return b
+}
+
+// Data sets a value of the template data, read by the template as
+// {{.Data.<key>}}, e.g. Data("reset_link", link).
+func (b *Builder) Data(key string, value any) *Builder {
+	if b.data == nil {
+		b.data = make(map[string]any)
+	}
+	b.data[key] = value
+	return b
+}
+
+// Attach attaches a file, the content type is guessed from the file name by
+// the mail client when empty.
+func (b *Builder) Attach(filename, contentType string, content []byte) *Builder {
+	b.attachments = append(b.attachments, models.Attachment{Filename: filename, ContentType: contentType, Content: content})
+	return b
+}
+
+// SendAt delays the email until t, at most the service's schedule.max_delay
+// ahead.
+func (b *Builder) SendAt(t time.Time) *Builder {
+	t = t.UTC()
+	b.sendAt = &t
+	return b
+}
+
+// IdempotencyKey sets the message ID, suffixed with the recipient index when
+// the email has several recipients. Without one random IDs are used.
+func (b *Builder) IdempotencyKey(key string) *Builder {
+	b.key = key
+	return b
+}
+
+// Build returns one message per recipient.
+func (b *Builder) Build() ([]Message, error) {
+	if len(b.recipients) == 0 {
+		return nil, errors.EmptyParamErr("user")
+	}
+
+	messages := make([]Message, len(b.recipients))
+	for i, recipient := range b.recipients {
+		id := b.key
+		switch {
+		case id == "":
+			id = uuid.NewString()
+		case len(b.recipients) > 1:
+			id = fmt.Sprintf("%s/%d", b.key, i)
+		}
+
+		messages[i] = Message{
+			ID: id,
+			Email: models.UserLinks{
+				Template:    b.template,
+				User:        recipient,
+				Problems:    b.problems,
+				Data:        b.data,
+				Attachments: b.attachments,
+				SendAt:      b.sendAt,
+			},
+		}
+	}
+	return messages, nil
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	// Go Internal Packages
+	"reflect"
+	"testing"
+	"time"
+
+	// Local Packages
+	errors "github.com/satya-ajayy/Emailer/errors"
+	models "github.com/satya-ajayy/Emailer/models"
+)
+
+func TestBuild(t *testing.T) {
+	paris := time.FixedZone("CEST", 2*60*60)
+	sendAt := time.Date(2026, 10, 19, 11, 30, 0, 0, paris)
+	problem := models.Problem{ID: "1", Name: "Two Sum", Link: "https://example.com/problems/1"}
+
+	tests := []struct {
+		name    string
+		email   *Builder
+		ids     []string
+		wantErr bool
+	}{
+		{name: "no recipients", email: NewEmail("problems").Problems(problem), wantErr: true},
+		{
+			name:  "key of one recipient",
+			email: NewEmail("problems").To("Ada", "ada@example.com").Problems(problem).IdempotencyKey("weekly-42"),
+			ids:   []string{"weekly-42"},
+		},
+		{
+			name: "key of several recipients",
+			email: NewEmail("problems").To("Ada", "ada@example.com").To("Alan", "alan@example.com").
+				Problems(problem).IdempotencyKey("weekly-42"),
+			ids: []string{"weekly-42/0", "weekly-42/1"},
+		},
+		{
+			name:  "random IDs",
+			email: NewEmail("problems").To("Ada", "ada@example.com").To("Alan", "alan@example.com").Problems(problem),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, err := tt.email.Build()
+			if tt.wantErr {
+				if errors.KindOf(err) != errors.Invalid {
+					t.Fatalf("Build() error = %v, want an invalid error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+
+			ids := make([]string, len(messages))
+			for i, message := range messages {
+				ids[i] = message.ID
+			}
+			if tt.ids == nil {
+				if len(ids) != 2 || ids[0] == "" || ids[0] == ids[1] {
+					t.Errorf("IDs = %v, want two distinct random IDs", ids)
+				}
+				return
+			}
+			if !reflect.DeepEqual(ids, tt.ids) {
+				t.Errorf("IDs = %v, want %v", ids, tt.ids)
+			}
+		})
+	}
+
+	messages, err := NewEmail("password-reset").ToUser(models.UserData{UserName: "Ada", MailID: "ada@example.com",
+		Locale: "fr"}).Data("reset_link", "https://example.com/reset").
+		Attach("report.pdf", "application/pdf", []byte("%PDF")).SendAt(sendAt).Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	want := models.UserLinks{
+		Template:    "password-reset",
+		User:        models.UserData{UserName: "Ada", MailID: "ada@example.com", Locale: "fr"},
+		Data:        map[string]any{"reset_link": "https://example.com/reset"},
+		Attachments: []models.Attachment{{Filename: "report.pdf", ContentType: "application/pdf", Content: []byte("%PDF")}},
+	}
+	got := messages[0].Email
+	if got.SendAt == nil || !got.SendAt.Equal(sendAt) || got.SendAt.Location() != time.UTC {
+		t.Errorf("send at = %v, want %v in UTC", got.SendAt, sendAt)
+	}
+	got.SendAt = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("email = %+v, want %+v", got, want)
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	record := models.Record{Headers: Message{ID: "weekly-42"}.Headers()}
+	for key, want := range map[string]string{
+		models.HeaderContentType:    "application/json",
+		models.HeaderSchemaVersion:  "1",
+		models.HeaderIdempotencyKey: "weekly-42",
+	} {
+		if value, _ := record.Header(key); value != want {
+			t.Errorf("header %s = %q, want %q", key, value, want)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	// Go Internal Packages
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	// Local Packages
+	config "github.com/satya-ajayy/Emailer/config"
+	errors "github.com/satya-ajayy/Emailer/errors"
+	metrics "github.com/satya-ajayy/Emailer/metrics"
+	models "github.com/satya-ajayy/Emailer/models"
+	pipeline "github.com/satya-ajayy/Emailer/pipeline"
+	processors "github.com/satya-ajayy/Emailer/services/processors"
+	templates "github.com/satya-ajayy/Emailer/templates"
+	alert "github.com/satya-ajayy/Emailer/utils/alert"
+
+	// External Packages
+	"go.uber.org/zap"
+)
+
+// cliTopic is the topic set on the records built by the commands, so they can
+// be told apart in the logs.
+const cliTopic = "cli"
+
+// SendOptions are the flags of the send command.
+type SendOptions struct {
+	File     string
+	To       string
+	Name     string
+	Template string
+	Locale   string
+	Timezone string
+}
+
+// RenderOptions are the flags of the render command.
+type RenderOptions struct {
+	Template string
+	Data     string
+	Fixture  string
+	Format   string
+	Out      string
+}
+
+// ReplayOptions are the flags of the replay command.
+type ReplayOptions struct {
+	File   string
+	DryRun bool
+}
+
+// Command holds what the commands share: the config, a logger writing to
+// stderr (stdout being the commands' output) and the mail processor wired as
+// in the server, without alerts.
+type Command struct {
+	Config    config.Config
+	Logger    *zap.Logger
+	Processor *processors.MailProcessor
+	Templates *templates.Registry
+	Metrics   *metrics.Metrics
+}
+
+// NewCommand loads the config and sets up the processor for a command,
+// exiting when either fails.
+func NewCommand(configPath string) *Command {
+	k, err := LoadConfig(configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	logger, _ := NewLogger(k, "stderr")
+	promMetrics := metrics.New(k.Application)
+	processor, registry, err := InitializeProcessor(k, logger, alert.Discard, promMetrics)
+	if err != nil {
+		logger.Fatal("cannot initialize processor", zap.Error(err))
+	}
+	return &Command{Config: k, Logger: logger, Processor: processor, Templates: registry, Metrics: promMetrics}
+}
+
+// Send sends one email, built from the JSON file (shaped as the Kafka
+// records) with the flags overriding its fields.
+func (c *Command) Send(ctx context.Context, opts SendOptions) error {
+	var userLinks models.UserLinks
+	if opts.File != "" {
+		content, err := os.ReadFile(opts.File)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %v", opts.File, err)
+		}
+		if err = json.Unmarshal(content, &userLinks); err != nil {
+			return fmt.Errorf("error decoding %s: %v", opts.File, err)
+		}
+	}
+	override(&userLinks.User.MailID, opts.To)
+	override(&userLinks.User.UserName, opts.Name)
+	override(&userLinks.Template, opts.Template)
+	override(&userLinks.User.Locale, opts.Locale)
+	override(&userLinks.User.Timezone, opts.Timezone)
+
+	value, err := json.Marshal(userLinks)
+	if err != nil {
+		return err
+	}
+	record := models.Record{Topic: cliTopic, Value: value}
+	for {
+		err = c.Processor.ProcessRecord(ctx, record)
+		var later *pipeline.Deferred
+		if !errors.As(err, &later) {
+			break
+		}
+
+		// scheduled emails are sent once due, the command waits for them
+		fmt.Printf("waiting until %s to send\n", later.Until.Format(time.RFC3339))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Until(later.Until)):
+		}
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("sent to %s\n", userLinks.User.MailID)
+	return nil
+}
+
+// override sets the field to the flag value, when the flag is given.
+func override(field *string, value string) {
+	if value != "" {
+		*field = value
+	}
+}
+
+// Render renders a template with the data file, or one of its fixtures, and
+// writes its HTML, text part or whole composed email as JSON.
+func (c *Command) Render(opts RenderOptions) error {
+	name := c.Templates.Resolve(opts.Template)
+	if !c.Templates.Has(name) {
+		return fmt.Errorf("template %q not found", name)
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if opts.Data != "" {
+		data, err = os.ReadFile(opts.Data)
+	} else {
+		data, err = c.Templates.Fixture(name, opts.Fixture)
+	}
+	if err != nil {
+		return fmt.Errorf("error reading template data: %v", err)
+	}
+
+	var userLinks models.UserLinks
+	if err = json.Unmarshal(data, &userLinks); err != nil {
+		return fmt.Errorf("error decoding template data: %v", err)
+	}
+	if err = c.Templates.Validate(name, data); err != nil {
+		return err
+	}
+	userLinks.Template = name
+	email, err := c.Processor.Compose(userLinks)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if opts.Out != "" {
+		f, err := os.Create(opts.Out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	switch opts.Format {
+	case "text":
+		_, err = io.WriteString(out, email.Text)
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(email)
+	default:
+		_, err = io.WriteString(out, email.HTML)
+	}
+	return err
+}
+
+// Replay feeds every line of a JSONL file, one record value per line, or of
+// the standard input when the file is empty or "-", through the records pipeline with
+// the configured retries. Failures are logged and the replay goes on, the
+// error reports how many lines failed.
+func (c *Command) Replay(ctx context.Context, opts ReplayOptions) error {
+	var (
+		source pipeline.Source = pipeline.NewStdin(c.Config.Kafka.RecordsPerPoll)
+		err    error
+	)
+	if opts.File != "" && opts.File != "-" {
+		source, err = pipeline.NewFile(opts.File, c.Config.Kafka.RecordsPerPoll)
+		if err != nil {
+			return err
+		}
+	}
+
+	var processor pipeline.MailProcessor = c.Processor
+	if opts.DryRun {
+		processor = dryRun{c.Processor}
+	}
+
+	records := pipeline.New(source, processor, pipelineOptions(c.Config), c.Metrics, c.Logger, alert.Discard)
+	if err = records.Run(ctx); err != nil {
+		return err
+	}
+
+	stats := records.Stats()
+	fmt.Printf("%d processed, %d failed\n", stats.Processed, stats.Failed)
+	if stats.Failed > 0 {
+		return fmt.Errorf("%d of %d lines failed", stats.Failed, stats.Processed+stats.Failed)
+	}
+	return nil
+}
+
+// dryRun validates and renders the records without sending them.
+type dryRun struct {
+	processor *processors.MailProcessor
+}
+
+func (d dryRun) ProcessRecord(ctx context.Context, record models.Record) error {
+	_, err := d.processor.Prepare(ctx, record)
+	return err
+}
+
+// LintTemplates composes every template with each of its fixtures, checking
+// the fixtures against the template's schema. The templates themselves were
+// parsed when the registry was created.
+func (c *Command) LintTemplates() error {
+	problems := 0
+	for _, name := range c.Templates.Names() {
+		fixtures, err := c.Templates.Fixtures(name)
+		if err != nil {
+			return err
+		}
+		if len(fixtures) == 0 {
+			fmt.Printf("%s: no fixtures to render\n", name)
+			continue
+		}
+
+		for _, fixture := range fixtures {
+			if err = c.lintFixture(name, fixture); err != nil {
+				fmt.Printf("%s/%s: %v\n", name, fixture, err)
+				problems++
+				continue
+			}
+			fmt.Printf("%s/%s: ok\n", name, fixture)
+		}
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("%d fixtures failed", problems)
+	}
+	return nil
+}
+
+func (c *Command) lintFixture(name, fixture string) error {
+	data, err := c.Templates.Fixture(name, fixture)
+	if err != nil {
+		return err
+	}
+	if err = c.Templates.Validate(name, data); err != nil {
+		return err
+	}
+
+	var userLinks models.UserLinks
+	if err = json.Unmarshal(data, &userLinks); err != nil {
+		return err
+	}
+	userLinks.Template = name
+	_, err = c.Processor.Compose(userLinks)
+	return err
+}
+
+// Run runs the command until it's done or interrupted, exiting non-zero when
+// it fails.
+func (c *Command) Run(run func(ctx context.Context) error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := run(ctx)
+	stop()
+	// os.Exit skips the deferred calls, flush the logs first
+	_ = c.Logger.Sync()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -3,110 +3,317 @@ package main
 import (
 	// Go Internal Packages
 	"context"
+	"fmt"
 	"log"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
+	_ "time/tzdata"
 
 	// Local Packages
-	config "emailer/config"
-	shttp "emailer/http"
-	kafka "emailer/kafka"
-	models "emailer/models"
-	health "emailer/services/health"
-	processors "emailer/services/processors"
-	slack "emailer/utils/slack"
+	config "github.com/satya-ajayy/Emailer/config"
+	errors "github.com/satya-ajayy/Emailer/errors"
+	shttp "github.com/satya-ajayy/Emailer/http"
+	kafka "github.com/satya-ajayy/Emailer/kafka"
+	metrics "github.com/satya-ajayy/Emailer/metrics"
+	models "github.com/satya-ajayy/Emailer/models"
+	pipeline "github.com/satya-ajayy/Emailer/pipeline"
+	rpc "github.com/satya-ajayy/Emailer/rpc"
+	health "github.com/satya-ajayy/Emailer/services/health"
+	ingest "github.com/satya-ajayy/Emailer/services/ingest"
+	processors "github.com/satya-ajayy/Emailer/services/processors"
+	templates "github.com/satya-ajayy/Emailer/templates"
+	tracing "github.com/satya-ajayy/Emailer/tracing"
+	alert "github.com/satya-ajayy/Emailer/utils/alert"
+	mailaddr "github.com/satya-ajayy/Emailer/utils/mailaddr"
 
 	// External Packages
 	"github.com/alecthomas/kingpin/v2"
 	_ "github.com/jsternberg/zap-logfmt"
 	"github.com/knadh/koanf"
 	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/env"
 	"github.com/knadh/koanf/providers/file"
 	"github.com/knadh/koanf/providers/rawbytes"
-	"github.com/twmb/franz-go/plugin/kprom"
+	"github.com/twmb/franz-go/pkg/kgo"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
 // InitializeServer sets up an HTTP server with health Service and starts kafka consumer.
-func InitializeServer(ctx context.Context, k config.Config, logger *zap.Logger) (*shttp.Server, error) {
-	// Slack Alert Sender
-	slackAlerter := slack.NewSender(k.Slack, k.IsProdMode)
+// The returned func applies the hot reloadable settings of a reloaded config.
+func InitializeServer(ctx context.Context, k config.Config, logger *zap.Logger) (*shttp.Server, func(config.Config) error, error) {
+	// Alerts, routed to the configured sinks
+	alerts, err := alert.New(ctx, logger, k.Alerts, k.Slack, k.IsProdMode, processors.NewDialer(k.Credentials))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	promMetrics := metrics.New(k.Application)
+	processor, registry, err := InitializeProcessor(k, logger, alerts, promMetrics)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if k.Templates.Watch {
+		go func() {
+			if err := registry.Watch(ctx); err != nil {
+				logger.Error("cannot watch templates", zap.Error(err))
+			}
+		}()
+	}
 
-	metrics := kprom.NewMetrics("emailer")
-	conf := &models.ConsumerConfig{
-		Brokers:        k.Kafka.Brokers,
-		Name:           k.Kafka.ConsumerName,
-		Topic:          k.Kafka.Topic,
-		RecordsPerPoll: k.Kafka.RecordsPerPoll,
+	connection, err := kafka.ConnectionOpts(k.Kafka)
+	if err != nil {
+		return nil, nil, err
+	}
+	consumer, err := kafka.NewConsumer(consumerConfig(k), connection, promMetrics, logger)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	processor := processors.NewProcessor(logger, k.Credentials)
-	consumer, err := kafka.NewConsumer(conf, processor, metrics, logger, slackAlerter)
+	emails, direct, err := InitializeIngest(ctx, k, connection, logger, processor, alerts, promMetrics)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
+	records := pipeline.New(consumer, processor, pipelineOptions(k), promMetrics, logger, alerts)
+	records.Track(emails)
+	records.Prioritize(processor)
 	go func() {
-		if err = consumer.Poll(ctx); err != nil {
+		if err = records.Run(ctx); err != nil {
 			logger.Fatal("cannot poll records from topic", zap.Error(err))
 		}
 	}()
 
+	if k.Auth.APIToken == "" || k.Auth.AdminToken == "" {
+		logger.Warn("auth tokens not set, the email and admin APIs are open")
+	}
 	healthSvc := health.NewService(logger, consumer)
-	server := shttp.NewServer(k.Prefix, logger, consumer, healthSvc)
-	return server, nil
-}
+	server := shttp.NewServer(k.Prefix, logger, consumer, healthSvc, processor, registry, promMetrics,
+		emails, rpc.NewServer(logger, emails, k.Auth.APIToken), k.Auth)
 
-// LoadConfig loads the default configuration and overrides it with the config file
-// specified by the path defined in the config flag
-func LoadConfig() *koanf.Koanf {
-	configPathMsg := "path to the application config file"
-	configPath := kingpin.Flag("config", configPathMsg).Short('c').Default("config.yml").String()
+	apply := func(k config.Config) error {
+		consumer.Apply(consumerConfig(k))
+		records.Apply(pipelineOptions(k))
+		if direct != nil {
+			direct.Apply(pipelineOptions(k))
+		}
+		processor.Apply(k.Credentials, k.Templates.InlineCSS)
+		if err := registry.Apply(k.Templates); err != nil {
+			return fmt.Errorf("error applying templates config: %v", err)
+		}
+		return alerts.Apply(ctx, k.Alerts, k.Slack, k.IsProdMode, processors.NewDialer(k.Credentials))
+	}
+	return server, apply, nil
+}
 
-	kingpin.Parse()
-	k := koanf.New(".")
-	_ = k.Load(rawbytes.Provider(config.DefaultConfig), yaml.Parser())
-	if *configPath != "" {
-		_ = k.Load(file.Provider(*configPath), yaml.Parser())
+// InitializeIngest sets up the ingest service of the HTTP and gRPC APIs. The
+// emails are produced to the Kafka topic, or queued and sent by the returned
+// pipeline of this instance in the direct mode.
+func InitializeIngest(ctx context.Context, k config.Config, connection []kgo.Opt, logger *zap.Logger,
+	processor *processors.MailProcessor, alerts alert.Notifier, promMetrics *metrics.Metrics) (*ingest.IngestService,
+	*pipeline.Pipeline, error) {
+	if k.Ingest.Mode == config.IngestProduce {
+		producer, err := kafka.NewProducer(connection, k.Kafka.Topic, promMetrics)
+		if err != nil {
+			return nil, nil, err
+		}
+		go func() {
+			<-ctx.Done()
+			producer.Close()
+		}()
+		return ingest.NewService(logger, processor, producer, k.Ingest), nil, nil
 	}
 
-	return k
+	queue := pipeline.NewQueue(k.Ingest.QueueSize)
+	emails := ingest.NewService(logger, processor, queue, k.Ingest)
+	direct := pipeline.New(queue.Source(k.Kafka.RecordsPerPoll), processor, pipelineOptions(k), promMetrics,
+		logger, alerts)
+	direct.Track(emails)
+	go func() {
+		if err := direct.Run(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("direct email pipeline stopped", zap.Error(err))
+		}
+	}()
+	return emails, direct, nil
 }
 
-func main() {
-	k := LoadConfig()
-	appKonf := config.Config{}
-
-	// Unmarshalling config into struct
-	err := k.Unmarshal("", &appKonf)
+// InitializeProcessor sets up the templates and the mail processor, shared by
+// the server and the commands.
+func InitializeProcessor(k config.Config, logger *zap.Logger, alerts alert.Notifier,
+	promMetrics *metrics.Metrics) (*processors.MailProcessor, *templates.Registry, error) {
+	registry, err := templates.NewRegistry(logger, k.Templates, alerts)
 	if err != nil {
-		log.Fatalf("error loading config: %v", err)
+		return nil, nil, err
 	}
 
-	// Validate the config loaded
-	if err = appKonf.Validate(); err != nil {
-		log.Fatalf("invalid configuration: %v", err)
+	routes, err := processors.NewRoutes(k.Kafka.Routes)
+	if err != nil {
+		return nil, nil, err
 	}
-
-	if !appKonf.IsProdMode {
-		k.Print()
+	if err = routes.Check(registry); err != nil {
+		return nil, nil, err
 	}
 
-	if !appKonf.Kafka.Consume {
-		log.Fatalf("kafka consumer is not enabled")
-	}
+	addresses := mailaddr.NewValidator(k.Addresses, net.DefaultResolver)
+	processor := processors.NewProcessor(logger, k.Credentials, registry, addresses, promMetrics,
+		k.Idempotency, k.Schedule, routes, k.Templates.InlineCSS)
+	return processor, registry, nil
+}
 
+// NewLogger builds the logfmt logger writing to the output, its level can be
+// changed through the returned AtomicLevel.
+func NewLogger(k config.Config, output string) (*zap.Logger, zap.AtomicLevel) {
 	cfg := zap.NewProductionConfig()
 	cfg.Encoding = "logfmt"
-	_ = cfg.Level.UnmarshalText([]byte(appKonf.Logger.Level))
+	_ = cfg.Level.UnmarshalText([]byte(k.Logger.Level))
 	cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 	cfg.InitialFields = make(map[string]any)
 	cfg.InitialFields["host"], _ = os.Hostname()
-	cfg.InitialFields["service"] = appKonf.Application
-	cfg.OutputPaths = []string{"stdout"}
+	cfg.InitialFields["service"] = k.Application
+	cfg.OutputPaths = []string{output}
 	logger, _ := cfg.Build()
+	return logger, cfg.Level
+}
+
+func consumerConfig(k config.Config) *models.ConsumerConfig {
+	topics, regex := k.Kafka.Subscriptions()
+	return &models.ConsumerConfig{
+		Name:             k.Kafka.ConsumerName,
+		Topics:           topics,
+		Regex:            regex,
+		RecordsPerPoll:   k.Kafka.RecordsPerPoll,
+		Rack:             k.Kafka.Rack,
+		SessionTimeout:   k.Kafka.SessionTimeout,
+		RebalanceTimeout: k.Kafka.RebalanceTimeout,
+	}
+}
+
+func pipelineOptions(k config.Config) pipeline.Options {
+	return pipeline.Options{
+		MaxAttempts:  k.Kafka.MaxAttempts,
+		RetryBackoff: k.Kafka.RetryBackoff,
+	}
+}
+
+// LoadConfig loads the default configuration and overrides it with the config file
+// at path, the environment variables and the secret files, then validates it
+func LoadConfig(path string) (config.Config, error) {
+	appKonf := config.Config{}
+
+	k := koanf.New(".")
+	_ = k.Load(rawbytes.Provider(config.DefaultConfig), yaml.Parser())
+	if path != "" {
+		// a missing file leaves the defaults, a broken one is an error
+		if err := k.Load(file.Provider(path), yaml.Parser()); err != nil && !os.IsNotExist(err) {
+			return appKonf, fmt.Errorf("error reading config file %s: %v", path, err)
+		}
+	}
+
+	// Environment variables override the files, e.g. EMAILER_CREDENTIALS__PASSWORD,
+	// then the <key>_file secrets are read
+	_ = k.Load(env.Provider(config.EnvPrefix, ".", config.EnvKey), nil)
+	if err := config.LoadSecretFiles(k); err != nil {
+		return appKonf, fmt.Errorf("error loading secrets: %v", err)
+	}
+
+	// Unmarshalling config into struct
+	if err := k.Unmarshal("", &appKonf); err != nil {
+		return appKonf, fmt.Errorf("error loading config: %v", err)
+	}
+
+	// Validate the config loaded
+	if err := appKonf.Validate(); err != nil {
+		var ve errors.ValidationErrors
+		if errors.As(err, &ve) {
+			return appKonf, fmt.Errorf("invalid configuration:\n%s", ve.Report())
+		}
+		return appKonf, fmt.Errorf("invalid configuration: %v", err)
+	}
+	return appKonf, nil
+}
+
+// ValidateConfig loads and validates the config file, printing the problems
+// found. It exits non-zero when the file is missing or invalid.
+func ValidateConfig(path string) {
+	if _, err := os.Stat(path); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot read config file: %v\n", err)
+		os.Exit(1)
+	}
+	if _, err := LoadConfig(path); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s is valid\n", path)
+}
+
+func main() {
+	configPathMsg := "path to the application config file"
+	configPath := kingpin.Flag("config", configPathMsg).Short('c').Default("config.yml").String()
+	serveCmd := kingpin.Command("serve", "consume the emails and serve the HTTP API").Default()
+
+	sendCmd := kingpin.Command("send", "send one email from a JSON file or flags")
+	var send SendOptions
+	sendCmd.Flag("file", "JSON file shaped as the Kafka records").Short('f').ExistingFileVar(&send.File)
+	sendCmd.Flag("to", "recipient address").StringVar(&send.To)
+	sendCmd.Flag("name", "recipient name").StringVar(&send.Name)
+	sendCmd.Flag("template", "template to send, the default one when empty").StringVar(&send.Template)
+	sendCmd.Flag("locale", "recipient locale").StringVar(&send.Locale)
+	sendCmd.Flag("timezone", "recipient IANA time zone").StringVar(&send.Timezone)
+
+	renderCmd := kingpin.Command("render", "render a template to stdout or a file")
+	var render RenderOptions
+	renderCmd.Arg("template", "template to render, the default one when empty").StringVar(&render.Template)
+	renderCmd.Flag("data", "JSON file with the template data").ExistingFileVar(&render.Data)
+	renderCmd.Flag("fixture", "stored fixture rendered when no data is given").Default("default").StringVar(&render.Fixture)
+	renderCmd.Flag("format", "html, text or json for the whole email").Default("html").EnumVar(&render.Format, "html", "text", "json")
+	renderCmd.Flag("out", "file to write to instead of stdout").Short('o').StringVar(&render.Out)
+
+	replayCmd := kingpin.Command("replay", "process a JSONL file or stdin, one record value per line")
+	var replay ReplayOptions
+	replayCmd.Arg("file", "JSONL file to replay, stdin when omitted or -").StringVar(&replay.File)
+	replayCmd.Flag("dry-run", "validate and render the records without sending them").BoolVar(&replay.DryRun)
+
+	validateCmd := kingpin.Command("validate-config", "check the config file and report every problem")
+
+	templatesCmd := kingpin.Command("templates", "work with the templates")
+	lintCmd := templatesCmd.Command("lint", "parse the templates and compose them with every fixture")
+
+	switch kingpin.Parse() {
+	case serveCmd.FullCommand():
+		Serve(*configPath)
+	case sendCmd.FullCommand():
+		cmd := NewCommand(*configPath)
+		cmd.Run(func(ctx context.Context) error { return cmd.Send(ctx, send) })
+	case renderCmd.FullCommand():
+		cmd := NewCommand(*configPath)
+		cmd.Run(func(context.Context) error { return cmd.Render(render) })
+	case replayCmd.FullCommand():
+		cmd := NewCommand(*configPath)
+		cmd.Run(func(ctx context.Context) error { return cmd.Replay(ctx, replay) })
+	case validateCmd.FullCommand():
+		ValidateConfig(*configPath)
+	case lintCmd.FullCommand():
+		cmd := NewCommand(*configPath)
+		cmd.Run(func(context.Context) error { return cmd.LintTemplates() })
+	}
+}
+
+// Serve consumes the emails and serves the HTTP API until interrupted.
+func Serve(configPath string) {
+	appKonf, err := LoadConfig(configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if !appKonf.IsProdMode {
+		appKonf.Print()
+	}
+
+	logger, level := NewLogger(appKonf, "stdout")
 	defer func() {
 		_ = logger.Sync()
 	}()
@@ -114,12 +321,47 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	srv, err := InitializeServer(ctx, appKonf, logger)
+	if appKonf.Tracing.Enabled {
+		exporter, err := tracing.NewExporter(ctx, appKonf.Tracing)
+		if err != nil {
+			logger.Fatal("cannot create trace exporter", zap.Error(err))
+		}
+		provider := tracing.NewProvider(appKonf.Application, exporter, appKonf.Tracing.SampleRatio)
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = provider.Shutdown(shutdownCtx)
+		}()
+	}
+
+	srv, apply, err := InitializeServer(ctx, appKonf, logger)
 	if err != nil {
 		logger.Fatal("cannot initialize server", zap.Error(err))
 	}
 
-	if err = srv.Listen(ctx, appKonf.Listen); err != nil {
+	// Reload the config on changes to the file and on SIGHUP
+	applied := appKonf
+	go WatchConfig(ctx, configPath, logger, func(next config.Config) {
+		hot, _ := config.Changes(applied, next)
+		if _, restart := config.Changes(appKonf, next); len(restart) > 0 {
+			logger.Warn("config changes need a restart to take effect", zap.Strings("keys", restart))
+		}
+		if len(hot) == 0 {
+			return
+		}
+
+		if err := level.UnmarshalText([]byte(next.Logger.Level)); err != nil {
+			logger.Error("invalid log level", zap.String("level", next.Logger.Level), zap.Error(err))
+		}
+		if err := apply(next); err != nil {
+			logger.Error("failed to apply config", zap.Strings("keys", hot), zap.Error(err))
+			return
+		}
+		applied = next
+		logger.Info("config reloaded", zap.Strings("keys", hot))
+	})
+
+	if err = srv.Listen(ctx, appKonf.Listen, appKonf.GRPCListen); err != nil {
 		logger.Fatal("cannot listen", zap.Error(err))
 	}
 }
@@ -0,0 +1,75 @@
+package main
+
+import (
+	// Go Internal Packages
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	// Local Packages
+	config "github.com/satya-ajayy/Emailer/config"
+
+	// External Packages
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// reloadDelay lets a burst of file events settle before reloading the config.
+const reloadDelay = 250 * time.Millisecond
+
+// WatchConfig reloads the config whenever the config file changes or the
+// process receives SIGHUP, and hands every valid config to apply. Invalid
+// configs are logged and the running one is kept. It blocks until the context
+// is canceled.
+func WatchConfig(ctx context.Context, path string, logger *zap.Logger, apply func(config.Config)) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	// The directory is watched rather than the file, as editors and mounted
+	// config maps replace the file instead of writing to it
+	var events chan fsnotify.Event
+	var errs chan error
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logger.Error("cannot watch config file", zap.Error(err))
+	} else {
+		defer watcher.Close()
+		if err = watcher.Add(filepath.Dir(path)); err != nil {
+			logger.Warn("cannot watch config file, reloading on SIGHUP only", zap.String("path", path), zap.Error(err))
+		} else {
+			events, errs = watcher.Events, watcher.Errors
+		}
+	}
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-events:
+			// config maps swap the ..data symlink the file points through
+			if name := filepath.Base(event.Name); name != filepath.Base(path) && name != "..data" {
+				continue
+			}
+			logger.Debug("config file changed", zap.String("event", event.String()))
+			timer.Reset(reloadDelay)
+		case err := <-errs:
+			logger.Error("config watcher error", zap.Error(err))
+		case <-hup:
+			logger.Info("received SIGHUP, reloading config")
+			timer.Reset(0)
+		case <-timer.C:
+			next, err := LoadConfig(path)
+			if err != nil {
+				logger.Error("failed to reload config, keeping the running one", zap.Error(err))
+				continue
+			}
+			apply(next)
+		}
+	}
+}
@@ -1,8 +1,22 @@
 package config
 
 import (
+	// Go Internal Packages
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
 	// Local Packages
-	errors "emailer/errors"
+	errors "github.com/satya-ajayy/Emailer/errors"
+	models "github.com/satya-ajayy/Emailer/models"
 )
 
 var DefaultConfig = []byte(`
@@ -13,6 +27,8 @@ logger:
 
 listen: ":2529"
 
+grpc_listen: ":2530"
+
 prefix: "/emailer"
 
 is_prod_mode: false
@@ -26,26 +42,114 @@ kafka:
   consume: true
   topic: "emails-to-send"
   records_per_poll: 50
+  max_attempts: 3
+  retry_backoff: "2s"
   consumer_name: "emailer"
+  client_id: "emailer"
+  rack: ""
+  session_timeout: "45s"
+  rebalance_timeout: "1m"
+  tls:
+    enabled: false
+    ca_file: ""
+    cert_file: ""
+    key_file: ""
+    server_name: ""
+  sasl:
+    mechanism: ""
+    username: ""
+    password: ""
+    password_file: ""
+    token: ""
+    token_file: ""
+  routes: []
+
+ingest:
+  mode: "produce"
+  max_batch: 100
+  queue_size: 1000
+  status_ttl: "24h"
+  max_statuses: 100000
+  watch_timeout: "1m"
+
+templates:
+  dir: "templates"
+  default: "problems"
+  default_locale: "en"
+  inline_css: true
+  watch: true
+
+addresses:
+  allow_disposable: false
+  allow_role_accounts: false
+  disposable_domains: []
+  role_accounts: []
+  check_mx: false
+
+idempotency:
+  ttl: "24h"
+  max_keys: 100000
+
+schedule:
+  max_delay: "15m"
+
+tracing:
+  enabled: false
+  endpoint: "localhost:4318"
+  insecure: true
+  sample_ratio: 1.0
 
 slack:
   webhook_url: "https://hooks.slack.com/services/your/webhook/url"
   send_alert_in_dev: true
+  bot_token: ""
+  channel: ""
+  window: "1m"
+  admin_url: "http://localhost:2529/emailer"
+  redact_pii: true
+  redact_patterns: []
+
+alerts:
+  sinks:
+    - name: "slack"
+      type: "slack"
+      min_severity: "warning"
+
+auth:
+  api_token: ""
+  api_token_file: ""
+  admin_token: ""
+  admin_token_file: ""
 
 credentials:
-  mail_id: "rsajay54@gmail.com"
-  password: "epin gbkx sgzb dhmi"
+  mail_id: "` + DefaultMailID + `"
+  send_as: []
+  password: ""
+  password_file: ""
 `)
 
+// DefaultMailID is the placeholder sender address, production refuses to
+// start with it.
+const DefaultMailID = "emailer@example.com"
+
 type Config struct {
 	Application string      `koanf:"application"`
 	Listen      string      `koanf:"listen"`
+	GRPCListen  string      `koanf:"grpc_listen"`
 	Prefix      string      `koanf:"prefix"`
 	Logger      Logger      `koanf:"logger"`
 	IsProdMode  bool        `koanf:"is_prod_mode"`
 	Mongo       Mongo       `koanf:"mongo"`
 	Slack       Slack       `koanf:"slack"`
+	Alerts      Alerts      `koanf:"alerts"`
 	Kafka       Kafka       `koanf:"kafka"`
+	Ingest      Ingest      `koanf:"ingest"`
+	Templates   Templates   `koanf:"templates"`
+	Addresses   Addresses   `koanf:"addresses"`
+	Tracing     Tracing     `koanf:"tracing"`
+	Idempotency Idempotency `koanf:"idempotency"`
+	Schedule    Schedule    `koanf:"schedule"`
+	Auth        Auth        `koanf:"auth"`
 	Credentials Credentials `koanf:"credentials"`
 }
 
@@ -54,16 +158,190 @@ type Logger struct {
 }
 
 type Kafka struct {
-	Brokers        []string `koanf:"brokers"`
-	Consume        bool     `koanf:"consume"`
-	Topic          string   `koanf:"topic"`
-	RecordsPerPoll int      `koanf:"records_per_poll"`
-	ConsumerName   string   `koanf:"consumer_name"`
+	Brokers        []string      `koanf:"brokers"`
+	Consume        bool          `koanf:"consume"`
+	Topic          string        `koanf:"topic"`
+	RecordsPerPoll int           `koanf:"records_per_poll"`
+	ConsumerName   string        `koanf:"consumer_name"`
+	MaxAttempts    int           `koanf:"max_attempts"`
+	RetryBackoff   time.Duration `koanf:"retry_backoff"`
+	// ClientID identifies the emailer in the broker logs and quotas.
+	ClientID string `koanf:"client_id"`
+	// Rack is the rack of the emailer, to fetch from the closest replicas.
+	Rack             string        `koanf:"rack"`
+	SessionTimeout   time.Duration `koanf:"session_timeout"`
+	RebalanceTimeout time.Duration `koanf:"rebalance_timeout"`
+	TLS              KafkaTLS      `koanf:"tls"`
+	SASL             KafkaSASL     `koanf:"sasl"`
+	// Routes are the topics consumed besides Topic, whose records are sent
+	// the way of their route.
+	Routes []Route `koanf:"routes"`
+}
+
+// DefaultRoute names the route of the records matching no route, e.g. the
+// ones of kafka.topic, in the metrics.
+const DefaultRoute = "default"
+
+// Route maps the records of a topic, or of the topics matching the regex
+// Pattern, to the template they're rendered with, the template whose schema
+// the payload must satisfy, their priority and the sender identity. The first
+// route matching a topic is used.
+type Route struct {
+	Name     string `koanf:"name"`
+	Topic    string `koanf:"topic"`
+	Pattern  string `koanf:"pattern"`
+	Template string `koanf:"template"`
+	Schema   string `koanf:"schema"`
+	// Priority orders the records of one poll, the higher first. It doesn't
+	// hold back the next polls, so urgent records of a later poll still wait
+	// for the whole current one.
+	Priority int    `koanf:"priority"`
+	Sender   Sender `koanf:"sender"`
+}
+
+// Sender overrides the sender of the emails of a route. The SMTP account
+// still authenticates as credentials.mail_id, so the address must be that one
+// or one of credentials.send_as.
+type Sender struct {
+	Name    string `koanf:"name"`
+	MailID  string `koanf:"mail_id"`
+	ReplyTo string `koanf:"reply_to"`
+}
+
+// Subscriptions returns the topics to consume, as regexes when any route has
+// a pattern since a consumer either consumes topics or regexes.
+func (k Kafka) Subscriptions() (topics []string, regex bool) {
+	for _, route := range k.Routes {
+		regex = regex || route.Pattern != ""
+	}
+
+	topics = []string{k.Topic}
+	for _, route := range k.Routes {
+		topics = append(topics, route.Topic)
+	}
+	if !regex {
+		return topics, false
+	}
+
+	patterns := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		if topic != "" {
+			patterns = append(patterns, "^"+regexp.QuoteMeta(topic)+"$")
+		}
+	}
+	for _, route := range k.Routes {
+		if route.Pattern != "" {
+			patterns = append(patterns, route.Pattern)
+		}
+	}
+	return patterns, true
+}
+
+// KafkaTLS configures TLS to the brokers. The CA, client certificate and key
+// are PEM, usually read from files with ca_file, cert_file and key_file.
+// Without a CA the system roots are trusted, and the server name defaults to
+// the broker host.
+type KafkaTLS struct {
+	Enabled    bool   `koanf:"enabled"`
+	CA         string `koanf:"ca"`
+	Cert       string `koanf:"cert"`
+	Key        Secret `koanf:"key"`
+	ServerName string `koanf:"server_name"`
+}
+
+// Kafka SASL mechanisms
+const (
+	SASLPlain       = "PLAIN"
+	SASLScramSHA256 = "SCRAM-SHA-256"
+	SASLScramSHA512 = "SCRAM-SHA-512"
+	SASLOAuthBearer = "OAUTHBEARER"
+)
+
+// KafkaSASL configures SASL authentication to the brokers, with the username
+// and password or, for OAUTHBEARER, the token.
+type KafkaSASL struct {
+	Mechanism string `koanf:"mechanism"`
+	Username  string `koanf:"username"`
+	Password  Secret `koanf:"password"`
+	Token     Secret `koanf:"token"`
+}
+
+// Ingest modes
+const (
+	IngestProduce = "produce"
+	IngestDirect  = "direct"
+)
+
+// Ingest configures the HTTP and gRPC email APIs. In the produce mode the
+// emails are produced to the Kafka topic, in the direct mode they're queued,
+// up to QueueSize, and sent by this instance. The status of the accepted
+// emails is kept for StatusTTL, up to MaxStatuses.
+type Ingest struct {
+	Mode        string        `koanf:"mode"`
+	MaxBatch    int           `koanf:"max_batch"`
+	QueueSize   int           `koanf:"queue_size"`
+	StatusTTL   time.Duration `koanf:"status_ttl"`
+	MaxStatuses int           `koanf:"max_statuses"`
+	// WatchTimeout bounds the status watches in the produce mode, where the
+	// emails may be sent by another instance.
+	WatchTimeout time.Duration `koanf:"watch_timeout"`
+}
+
+type Templates struct {
+	Dir           string `koanf:"dir"`
+	Default       string `koanf:"default"`
+	DefaultLocale string `koanf:"default_locale"`
+	InlineCSS     bool   `koanf:"inline_css"`
+	Watch         bool   `koanf:"watch"`
+}
+
+// Addresses lives in models so the client can mirror it without this package.
+type Addresses = models.Addresses
+
+type Idempotency struct {
+	TTL     time.Duration `koanf:"ttl"`
+	MaxKeys int           `koanf:"max_keys"`
+}
+
+// Schedule bounds how far ahead emails can be scheduled with send_at. The
+// scheduled emails wait in memory, attachments included, until they're due,
+// so the delay bounds that backlog to the emails scheduled within it. It also
+// bounds how long their partition stays uncommitted, i.e. how many records a
+// restart consumes again.
+type Schedule struct {
+	MaxDelay time.Duration `koanf:"max_delay"`
+}
+
+type Tracing struct {
+	Enabled     bool    `koanf:"enabled"`
+	Endpoint    string  `koanf:"endpoint"`
+	Insecure    bool    `koanf:"insecure"`
+	SampleRatio float64 `koanf:"sample_ratio"`
+}
+
+// Auth holds the bearer tokens of the API. The APIToken gates sending emails
+// over HTTP and gRPC and the AdminToken the admin endpoints, an empty token
+// leaves them open, which only development allows.
+type Auth struct {
+	APIToken   Secret `koanf:"api_token"`
+	AdminToken Secret `koanf:"admin_token"`
 }
 
 type Credentials struct {
-	MailID   string `koanf:"mail_id"`
-	Password string `koanf:"password"`
+	MailID string `koanf:"mail_id"`
+	// SendAs are the other addresses the account may send as, e.g. its
+	// aliases, that the routes can use as their sender.
+	SendAs   []string `koanf:"send_as"`
+	Password Secret   `koanf:"password"`
+}
+
+// CanSendAs reports whether the account may send as the address, SMTP servers
+// usually reject or rewrite the other From addresses.
+func (c Credentials) CanSendAs(address string) bool {
+	if strings.EqualFold(address, c.MailID) {
+		return true
+	}
+	return slices.ContainsFunc(c.SendAs, func(alias string) bool { return strings.EqualFold(address, alias) })
 }
 
 type Mongo struct {
@@ -71,11 +349,54 @@ type Mongo struct {
 }
 
 type Slack struct {
-	WebhookURL     string `koanf:"webhook_url"`
-	SendAlertInDev bool   `koanf:"send_alert_in_dev"`
+	WebhookURL     Secret        `koanf:"webhook_url"`
+	SendAlertInDev bool          `koanf:"send_alert_in_dev"`
+	BotToken       Secret        `koanf:"bot_token"`
+	Channel        string        `koanf:"channel"`
+	Window         time.Duration `koanf:"window"`
+	AdminURL       string        `koanf:"admin_url"`
+	RedactPII      bool          `koanf:"redact_pii"`
+	RedactPatterns []string      `koanf:"redact_patterns"`
 }
 
-// Validate validates the configuration
+// Alert sink types
+const (
+	SinkSlack     = "slack"
+	SinkWebhook   = "webhook"
+	SinkTeams     = "teams"
+	SinkPagerDuty = "pagerduty"
+	SinkEmail     = "email"
+)
+
+// alertSeverities are the accepted alert sink min_severity values.
+var alertSeverities = map[string]bool{"": true, "info": true, "warning": true, "error": true, "critical": true}
+
+type Alerts struct {
+	Sinks []AlertSink `koanf:"sinks"`
+}
+
+// AlertSink is a destination for alerts. Only the events at or above
+// MinSeverity are sent and, when set, only the ones from the Sources (e.g.
+// "kafka", "templates") and about records of the Topics.
+type AlertSink struct {
+	Name        string   `koanf:"name"`
+	Type        string   `koanf:"type"`
+	MinSeverity string   `koanf:"min_severity"`
+	Sources     []string `koanf:"sources"`
+	Topics      []string `koanf:"topics"`
+	URL         Secret   `koanf:"url"`
+	Secret      Secret   `koanf:"secret"`
+	RoutingKey  Secret   `koanf:"routing_key"`
+	To          []string `koanf:"to"`
+}
+
+// logLevels are the accepted logger.level values.
+var logLevels = map[string]bool{"debug": true, "info": true, "warn": true, "error": true, "dpanic": true, "panic": true, "fatal": true}
+
+// alertSources are the accepted alert sink sources.
+var alertSources = map[string]bool{"kafka": true, "templates": true}
+
+// Validate validates the configuration, reporting every problem at once.
 func (c *Config) Validate() error {
 	ve := errors.ValidationErrs()
 
@@ -84,19 +405,327 @@ func (c *Config) Validate() error {
 	}
 	if c.Listen == "" {
 		ve.Add("listen", "cannot be empty")
+	} else if !isHostPort(c.Listen, true) {
+		ve.Add("listen", "must be an address such as :2529 or 127.0.0.1:2529")
+	}
+	if c.GRPCListen == "" {
+		ve.Add("grpc_listen", "cannot be empty")
+	} else if !isHostPort(c.GRPCListen, true) {
+		ve.Add("grpc_listen", "must be an address such as :2530 or 127.0.0.1:2530")
+	} else if c.GRPCListen == c.Listen {
+		ve.Add("grpc_listen", "must differ from listen")
 	}
 	if c.Logger.Level == "" {
 		ve.Add("logger.level", "cannot be empty")
+	} else if !logLevels[c.Logger.Level] {
+		ve.Add("logger.level", "must be one of debug, info, warn, error, dpanic, panic or fatal")
 	}
 	if c.Prefix == "" {
 		ve.Add("prefix", "cannot be empty")
+	} else if !strings.HasPrefix(c.Prefix, "/") || strings.HasSuffix(c.Prefix, "/") {
+		ve.Add("prefix", "must start with a slash and not end with one")
 	}
 	if c.Mongo.URI == "" {
 		ve.Add("mongo.uri", "cannot be empty")
+	} else if !isURL(c.Mongo.URI, "mongodb", "mongodb+srv") {
+		ve.Add("mongo.uri", "must be a mongodb:// or mongodb+srv:// URI")
+	}
+
+	c.validateKafka(ve)
+
+	if c.Ingest.Mode != IngestProduce && c.Ingest.Mode != IngestDirect {
+		ve.Add("ingest.mode", "must be produce or direct")
+	}
+	if c.Ingest.MaxBatch < 1 {
+		ve.Add("ingest.max_batch", "must be at least 1")
+	}
+	if c.Ingest.QueueSize < 1 {
+		ve.Add("ingest.queue_size", "must be at least 1")
+	}
+	if c.Ingest.StatusTTL <= 0 {
+		ve.Add("ingest.status_ttl", "must be positive")
 	}
-	if c.Slack.WebhookURL == "" {
-		ve.Add("slack.webhook_url", "cannot be empty")
+	if c.Ingest.MaxStatuses < 1 {
+		ve.Add("ingest.max_statuses", "must be at least 1")
 	}
+	if c.Ingest.WatchTimeout <= 0 {
+		ve.Add("ingest.watch_timeout", "must be positive")
+	}
+
+	if c.Templates.Default == "" {
+		ve.Add("templates.default", "cannot be empty")
+	}
+	if c.Templates.DefaultLocale == "" {
+		ve.Add("templates.default_locale", "cannot be empty")
+	}
+	if c.Templates.Watch && c.Templates.Dir == "" {
+		ve.Add("templates.dir", "cannot be empty when watch is set")
+	}
+	for i, domain := range c.Addresses.DisposableDomains {
+		if domain == "" || strings.ContainsAny(domain, "@ ") {
+			ve.Add(fmt.Sprintf("addresses.disposable_domains.%d", i), "must be a domain")
+		}
+	}
+	if c.Idempotency.MaxKeys < 1 {
+		ve.Add("idempotency.max_keys", "must be at least 1")
+	}
+	if c.Idempotency.TTL <= 0 {
+		ve.Add("idempotency.ttl", "must be positive")
+	}
+	if c.Schedule.MaxDelay < 0 {
+		ve.Add("schedule.max_delay", "cannot be negative")
+	}
+	if c.Credentials.MailID == "" {
+		ve.Add("credentials.mail_id", "cannot be empty")
+	} else if !isEmail(c.Credentials.MailID) {
+		ve.Add("credentials.mail_id", "must be an email address")
+	}
+	for i, alias := range c.Credentials.SendAs {
+		if !isEmail(alias) {
+			ve.Add(fmt.Sprintf("credentials.send_as.%d", i), "must be an email address")
+		}
+	}
+	if c.IsProdMode && c.Credentials.MailID == DefaultMailID {
+		ve.Add("credentials.mail_id", "must be set in production")
+	}
+	if c.IsProdMode && c.Credentials.Password == "" {
+		ve.Add("credentials.password", "must be set in production")
+	}
+	if c.IsProdMode && c.Auth.APIToken == "" {
+		ve.Add("auth.api_token", "must be set in production")
+	}
+	if c.IsProdMode && c.Auth.AdminToken == "" {
+		ve.Add("auth.admin_token", "must be set in production")
+	}
+	if c.Tracing.Enabled && c.Tracing.Endpoint == "" {
+		ve.Add("tracing.endpoint", "cannot be empty")
+	} else if c.Tracing.Enabled && !isHostPort(c.Tracing.Endpoint, false) {
+		ve.Add("tracing.endpoint", "must be a host:port address")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		ve.Add("tracing.sample_ratio", "must be between 0 and 1")
+	}
+
+	c.validateSlack(ve)
+	c.validateAlerts(ve)
 
 	return ve.Err()
 }
+
+func (c *Config) validateKafka(ve *errors.ValidationErrorBuilder) {
+	if !c.Kafka.Consume {
+		ve.Add("kafka.consume", "must be true, records are only received from Kafka")
+	}
+	if len(c.Kafka.Brokers) == 0 {
+		ve.Add("kafka.brokers", "cannot be empty")
+	}
+	for i, broker := range c.Kafka.Brokers {
+		if !isHostPort(broker, false) {
+			ve.Add(fmt.Sprintf("kafka.brokers.%d", i), "must be a host:port address")
+		}
+	}
+	if c.Kafka.Topic == "" {
+		ve.Add("kafka.topic", "cannot be empty")
+	}
+	if c.Kafka.ConsumerName == "" {
+		ve.Add("kafka.consumer_name", "cannot be empty")
+	}
+	if c.Kafka.RecordsPerPoll < 1 || c.Kafka.RecordsPerPoll > 10000 {
+		ve.Add("kafka.records_per_poll", "must be between 1 and 10000")
+	}
+	if c.Kafka.MaxAttempts < 1 {
+		ve.Add("kafka.max_attempts", "must be at least 1")
+	}
+	if c.Kafka.RetryBackoff < 0 {
+		ve.Add("kafka.retry_backoff", "cannot be negative")
+	}
+	if c.Kafka.ClientID == "" {
+		ve.Add("kafka.client_id", "cannot be empty")
+	}
+	if c.Kafka.SessionTimeout <= 0 {
+		ve.Add("kafka.session_timeout", "must be positive")
+	}
+	if c.Kafka.RebalanceTimeout <= 0 {
+		ve.Add("kafka.rebalance_timeout", "must be positive")
+	}
+
+	c.validateKafkaTLS(ve)
+	c.validateRoutes(ve)
+
+	sasl := c.Kafka.SASL
+	switch sasl.Mechanism {
+	case "":
+	case SASLPlain, SASLScramSHA256, SASLScramSHA512:
+		if sasl.Username == "" {
+			ve.Add("kafka.sasl.username", "cannot be empty with "+sasl.Mechanism)
+		}
+		if sasl.Password == "" {
+			ve.Add("kafka.sasl.password", "cannot be empty with "+sasl.Mechanism)
+		}
+	case SASLOAuthBearer:
+		if sasl.Token == "" {
+			ve.Add("kafka.sasl.token", "cannot be empty with "+sasl.Mechanism)
+		}
+	default:
+		ve.Add("kafka.sasl.mechanism", "must be one of PLAIN, SCRAM-SHA-256, SCRAM-SHA-512 or OAUTHBEARER")
+	}
+}
+
+func (c *Config) validateRoutes(ve *errors.ValidationErrorBuilder) {
+	names := map[string]bool{DefaultRoute: true}
+	topics := map[string]bool{c.Kafka.Topic: true}
+	for i, route := range c.Kafka.Routes {
+		field := fmt.Sprintf("kafka.routes.%d", i)
+		if route.Name == "" {
+			ve.Add(field+".name", "cannot be empty")
+		} else if names[route.Name] {
+			ve.Add(field+".name", "must be unique and not "+DefaultRoute)
+		}
+		names[route.Name] = true
+
+		switch {
+		case route.Topic == "" && route.Pattern == "":
+			ve.Add(field+".topic", "cannot be empty without pattern")
+		case route.Topic != "" && route.Pattern != "":
+			ve.Add(field+".pattern", "cannot be set along with topic")
+		case route.Topic != "":
+			if topics[route.Topic] {
+				ve.Add(field+".topic", "must differ from kafka.topic and the other routes")
+			}
+			topics[route.Topic] = true
+		default:
+			if _, err := regexp.Compile(route.Pattern); err != nil {
+				ve.Add(field+".pattern", "must be a valid regex")
+			}
+		}
+
+		if route.Sender.MailID != "" && !isEmail(route.Sender.MailID) {
+			ve.Add(field+".sender.mail_id", "must be an email address")
+		} else if route.Sender.MailID != "" && !c.Credentials.CanSendAs(route.Sender.MailID) {
+			ve.Add(field+".sender.mail_id", "must be credentials.mail_id or listed in credentials.send_as")
+		}
+		if route.Sender.ReplyTo != "" && !isEmail(route.Sender.ReplyTo) {
+			ve.Add(field+".sender.reply_to", "must be an email address")
+		}
+	}
+}
+
+func (c *Config) validateKafkaTLS(ve *errors.ValidationErrorBuilder) {
+	conf := c.Kafka.TLS
+	if !conf.Enabled {
+		if conf.CA != "" || conf.Cert != "" || conf.Key != "" || conf.ServerName != "" {
+			ve.Add("kafka.tls.enabled", "must be true when the tls settings are set")
+		}
+		return
+	}
+
+	if conf.CA != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(conf.CA)) {
+		ve.Add("kafka.tls.ca", "must contain PEM certificates")
+	}
+	switch {
+	case conf.Cert == "" && conf.Key == "":
+	case conf.Cert == "":
+		ve.Add("kafka.tls.cert", "cannot be empty when key is set")
+	case conf.Key == "":
+		ve.Add("kafka.tls.key", "cannot be empty when cert is set")
+	default:
+		if _, err := tls.X509KeyPair([]byte(conf.Cert), []byte(conf.Key.Value())); err != nil {
+			ve.Add("kafka.tls.cert", "must be a PEM certificate matching the key")
+		}
+	}
+}
+
+func (c *Config) validateSlack(ve *errors.ValidationErrorBuilder) {
+	if c.Slack.WebhookURL != "" && !isURL(c.Slack.WebhookURL.Value(), "https") {
+		ve.Add("slack.webhook_url", "must be an https URL")
+	}
+	if c.Slack.BotToken != "" && c.Slack.Channel == "" {
+		ve.Add("slack.channel", "cannot be empty when bot_token is set")
+	}
+	if c.Slack.Window <= 0 {
+		ve.Add("slack.window", "must be positive")
+	}
+	if c.Slack.AdminURL != "" && !isURL(c.Slack.AdminURL, "http", "https") {
+		ve.Add("slack.admin_url", "must be an http or https URL")
+	}
+	for _, pattern := range c.Slack.RedactPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			ve.Add("slack.redact_patterns", "contains an invalid pattern "+pattern)
+		}
+	}
+}
+
+func (c *Config) validateAlerts(ve *errors.ValidationErrorBuilder) {
+	names := make(map[string]bool)
+	for i, sink := range c.Alerts.Sinks {
+		field := fmt.Sprintf("alerts.sinks.%d", i)
+		if sink.Name == "" {
+			ve.Add(field+".name", "cannot be empty")
+		} else if names[sink.Name] {
+			ve.Add(field+".name", "must be unique")
+		}
+		names[sink.Name] = true
+
+		if !alertSeverities[sink.MinSeverity] {
+			ve.Add(field+".min_severity", "must be one of info, warning, error or critical")
+		}
+		for _, source := range sink.Sources {
+			if !alertSources[source] {
+				ve.Add(field+".sources", "must only contain kafka or templates")
+			}
+		}
+		if sink.URL != "" && !isURL(sink.URL.Value(), "http", "https") {
+			ve.Add(field+".url", "must be an http or https URL")
+		}
+
+		switch sink.Type {
+		case SinkSlack:
+			if sink.URL == "" && c.Slack.WebhookURL == "" && c.Slack.BotToken == "" {
+				ve.Add(field+".url", "cannot be empty without slack.webhook_url or slack.bot_token")
+			}
+		case SinkWebhook, SinkTeams:
+			if sink.URL == "" {
+				ve.Add(field+".url", "cannot be empty")
+			}
+		case SinkPagerDuty:
+			if sink.RoutingKey == "" {
+				ve.Add(field+".routing_key", "cannot be empty")
+			}
+		case SinkEmail:
+			if len(sink.To) == 0 {
+				ve.Add(field+".to", "cannot be empty")
+			}
+			for _, to := range sink.To {
+				if !isEmail(to) {
+					ve.Add(field+".to", "contains an invalid email address "+to)
+				}
+			}
+		default:
+			ve.Add(field+".type", "must be one of slack, webhook, teams, pagerduty or email")
+		}
+	}
+}
+
+// isHostPort reports whether addr is a host:port address with a valid port.
+// The host can be left out when allowed, e.g. ":2529" to listen on all
+// interfaces.
+func isHostPort(addr string, emptyHost bool) bool {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || (host == "" && !emptyHost) {
+		return false
+	}
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n < 65536
+}
+
+// isURL reports whether raw is an absolute URL with a host and one of the schemes.
+func isURL(raw string, schemes ...string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && u.Host != "" && slices.Contains(schemes, u.Scheme)
+}
+
+// isEmail reports whether s is a bare email address, without a display name.
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
@@ -0,0 +1,241 @@
+package config
+
+import (
+	// Go Internal Packages
+	"testing"
+	"time"
+
+	// Local Packages
+	errors "github.com/satya-ajayy/Emailer/errors"
+
+	// External Packages
+	"github.com/knadh/koanf"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/rawbytes"
+)
+
+// defaults returns the config of DefaultConfig.
+func defaults(t *testing.T) Config {
+	t.Helper()
+	k := koanf.New(".")
+	if err := k.Load(rawbytes.Provider(DefaultConfig), yaml.Parser()); err != nil {
+		t.Fatal(err)
+	}
+	var conf Config
+	if err := k.Unmarshal("", &conf); err != nil {
+		t.Fatal(err)
+	}
+	return conf
+}
+
+func TestRouteSender(t *testing.T) {
+	tests := []struct {
+		name   string
+		sender string
+		sendAs []string
+		errs   map[string]string
+	}{
+		{name: "no sender"},
+		{name: "credentials address", sender: "Emailer@Example.com"},
+		{name: "alias", sender: "hello@acme.com", sendAs: []string{"news@acme.com", "Hello@acme.com"}},
+		{
+			name:   "other address",
+			sender: "hello@acme.com",
+			sendAs: []string{"news@acme.com"},
+			errs:   map[string]string{"kafka.routes.0.sender.mail_id": "must be credentials.mail_id or listed in credentials.send_as"},
+		},
+		{
+			name:   "invalid address",
+			sender: "hello",
+			errs:   map[string]string{"kafka.routes.0.sender.mail_id": "must be an email address"},
+		},
+		{
+			name:   "invalid alias",
+			sendAs: []string{"news"},
+			errs:   map[string]string{"credentials.send_as.0": "must be an email address"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := defaults(t)
+			conf.Credentials.SendAs = tt.sendAs
+			conf.Kafka.Routes = []Route{{Name: "welcome", Topic: "welcome-emails", Sender: Sender{MailID: tt.sender}}}
+
+			checkErrors(t, conf, tt.errs)
+		})
+	}
+}
+
+// checkErrors checks that validating conf reports exactly the field errors.
+func checkErrors(t *testing.T, conf Config, errs map[string]string) {
+	t.Helper()
+	got := map[string]string{}
+	var ve errors.ValidationErrors
+	if err := conf.Validate(); errors.As(err, &ve) {
+		for _, fe := range ve {
+			got[fe.Field] = fe.Error
+		}
+	} else if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if len(got) != len(errs) {
+		t.Fatalf("Validate() errors = %v, want %v", got, errs)
+	}
+	for field, want := range errs {
+		if got[field] != want {
+			t.Errorf("%s: %q, want %q", field, got[field], want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(*Config)
+		errs map[string]string
+	}{
+		{name: "defaults", edit: func(*Config) {}},
+		{
+			name: "tls settings without tls",
+			edit: func(c *Config) { c.Kafka.TLS.ServerName = "kafka.internal" },
+			errs: map[string]string{"kafka.tls.enabled": "must be true when the tls settings are set"},
+		},
+		{
+			name: "tls CA not PEM",
+			edit: func(c *Config) { c.Kafka.TLS = KafkaTLS{Enabled: true, CA: "not a certificate"} },
+			errs: map[string]string{"kafka.tls.ca": "must contain PEM certificates"},
+		},
+		{
+			name: "tls key without cert",
+			edit: func(c *Config) { c.Kafka.TLS = KafkaTLS{Enabled: true, Key: "key"} },
+			errs: map[string]string{"kafka.tls.cert": "cannot be empty when key is set"},
+		},
+		{
+			name: "tls cert without key",
+			edit: func(c *Config) { c.Kafka.TLS = KafkaTLS{Enabled: true, Cert: "cert"} },
+			errs: map[string]string{"kafka.tls.key": "cannot be empty when cert is set"},
+		},
+		{
+			name: "tls cert not matching the key",
+			edit: func(c *Config) { c.Kafka.TLS = KafkaTLS{Enabled: true, Cert: "cert", Key: "key"} },
+			errs: map[string]string{"kafka.tls.cert": "must be a PEM certificate matching the key"},
+		},
+		{
+			name: "sasl without credentials",
+			edit: func(c *Config) { c.Kafka.SASL = KafkaSASL{Mechanism: SASLScramSHA512} },
+			errs: map[string]string{
+				"kafka.sasl.username": "cannot be empty with SCRAM-SHA-512",
+				"kafka.sasl.password": "cannot be empty with SCRAM-SHA-512",
+			},
+		},
+		{
+			name: "sasl oauth without token",
+			edit: func(c *Config) { c.Kafka.SASL = KafkaSASL{Mechanism: SASLOAuthBearer, Username: "emailer"} },
+			errs: map[string]string{"kafka.sasl.token": "cannot be empty with OAUTHBEARER"},
+		},
+		{
+			name: "sasl unknown mechanism",
+			edit: func(c *Config) { c.Kafka.SASL = KafkaSASL{Mechanism: "GSSAPI"} },
+			errs: map[string]string{
+				"kafka.sasl.mechanism": "must be one of PLAIN, SCRAM-SHA-256, SCRAM-SHA-512 or OAUTHBEARER",
+			},
+		},
+		{
+			name: "duplicate routes",
+			edit: func(c *Config) {
+				c.Kafka.Routes = []Route{
+					{Name: "welcome", Topic: "welcome-emails"},
+					{Name: "welcome", Topic: "welcome-emails"},
+					{Name: DefaultRoute, Topic: c.Kafka.Topic},
+				}
+			},
+			errs: map[string]string{
+				"kafka.routes.1.name":  "must be unique and not default",
+				"kafka.routes.1.topic": "must differ from kafka.topic and the other routes",
+				"kafka.routes.2.name":  "must be unique and not default",
+				"kafka.routes.2.topic": "must differ from kafka.topic and the other routes",
+			},
+		},
+		{
+			name: "route topics and patterns",
+			edit: func(c *Config) {
+				c.Kafka.Routes = []Route{
+					{Name: "none"},
+					{Name: "both", Topic: "welcome-emails", Pattern: "^welcome-.*"},
+					{Name: "invalid", Pattern: "(", Sender: Sender{ReplyTo: "support"}},
+				}
+			},
+			errs: map[string]string{
+				"kafka.routes.0.topic":           "cannot be empty without pattern",
+				"kafka.routes.1.pattern":         "cannot be set along with topic",
+				"kafka.routes.2.pattern":         "must be a valid regex",
+				"kafka.routes.2.sender.reply_to": "must be an email address",
+			},
+		},
+		{
+			name: "negative schedule delay",
+			edit: func(c *Config) { c.Schedule.MaxDelay = -time.Minute },
+			errs: map[string]string{"schedule.max_delay": "cannot be negative"},
+		},
+		{
+			name: "no schedule delay",
+			edit: func(c *Config) { c.Schedule.MaxDelay = 0 },
+		},
+		{
+			name: "sinks",
+			edit: func(c *Config) {
+				c.Slack.WebhookURL = ""
+				c.Alerts.Sinks = []AlertSink{
+					{Name: "ops", Type: SinkSlack, MinSeverity: "error"},
+					{Name: "ops", Type: SinkWebhook, MinSeverity: "loud", Sources: []string{"smtp"}, URL: "ftp://hooks"},
+					{Name: "pager", Type: SinkPagerDuty, MinSeverity: "critical"},
+					{Name: "mail", Type: SinkEmail, MinSeverity: "warning", To: []string{"ops"}},
+					{Type: "sms", MinSeverity: "info"},
+				}
+			},
+			errs: map[string]string{
+				"alerts.sinks.0.url":          "cannot be empty without slack.webhook_url or slack.bot_token",
+				"alerts.sinks.1.name":         "must be unique",
+				"alerts.sinks.1.min_severity": "must be one of info, warning, error or critical",
+				"alerts.sinks.1.sources":      "must only contain kafka or templates",
+				"alerts.sinks.1.url":          "must be an http or https URL",
+				"alerts.sinks.2.routing_key":  "cannot be empty",
+				"alerts.sinks.3.to":           "contains an invalid email address ops",
+				"alerts.sinks.4.name":         "cannot be empty",
+				"alerts.sinks.4.type":         "must be one of slack, webhook, teams, pagerduty or email",
+			},
+		},
+		{
+			name: "slack sink with the slack webhook",
+			edit: func(c *Config) {
+				c.Alerts.Sinks = []AlertSink{{Name: "ops", Type: SinkSlack, MinSeverity: "error"}}
+			},
+		},
+		{
+			name: "production secrets",
+			edit: func(c *Config) {
+				c.IsProdMode = true
+				c.Credentials.Password = "password"
+			},
+			errs: map[string]string{
+				"credentials.mail_id": "must be set in production",
+				"auth.api_token":      "must be set in production",
+				"auth.admin_token":    "must be set in production",
+			},
+		},
+		{
+			name: "listen addresses",
+			edit: func(c *Config) { c.GRPCListen = c.Listen },
+			errs: map[string]string{"grpc_listen": "must differ from listen"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := defaults(t)
+			tt.edit(&conf)
+			checkErrors(t, conf, tt.errs)
+		})
+	}
+}
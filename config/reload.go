@@ -0,0 +1,63 @@
+package config
+
+import (
+	// Go Internal Packages
+	"reflect"
+	"sort"
+	"strings"
+
+	// External Packages
+	"github.com/knadh/koanf"
+	"github.com/knadh/koanf/providers/structs"
+)
+
+// hotReloadable are the keys, or key prefixes ending with a dot, applied on a
+// config reload. Changes to the other keys need a restart.
+var hotReloadable = []string{
+	"logger.level",
+	"kafka.records_per_poll",
+	"kafka.max_attempts",
+	"kafka.retry_backoff",
+	"templates.default",
+	"templates.default_locale",
+	"templates.inline_css",
+	"slack.",
+	"alerts.",
+	"credentials.",
+}
+
+// Changes returns the sorted keys whose values differ between the configs,
+// split into the ones applied on reload and the ones needing a restart.
+func Changes(old, new Config) (hot, restart []string) {
+	oldKonf, newKonf := koanf.New("."), koanf.New(".")
+	_ = oldKonf.Load(structs.Provider(old, "koanf"), nil)
+	_ = newKonf.Load(structs.Provider(new, "koanf"), nil)
+
+	keys := make(map[string]bool)
+	for _, key := range append(oldKonf.Keys(), newKonf.Keys()...) {
+		keys[key] = true
+	}
+
+	for key := range keys {
+		if reflect.DeepEqual(oldKonf.Get(key), newKonf.Get(key)) {
+			continue
+		}
+		if isHotReloadable(key) {
+			hot = append(hot, key)
+		} else {
+			restart = append(restart, key)
+		}
+	}
+	sort.Strings(hot)
+	sort.Strings(restart)
+	return hot, restart
+}
+
+func isHotReloadable(key string) bool {
+	for _, hr := range hotReloadable {
+		if key == hr || (strings.HasSuffix(hr, ".") && strings.HasPrefix(key, hr)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	// Go Internal Packages
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	// External Packages
+	"github.com/knadh/koanf"
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/providers/structs"
+)
+
+// redacted is how a non-empty Secret is printed.
+const redacted = "[redacted]"
+
+// secretFileSuffix marks a key whose value is read from a file, e.g.
+// credentials.password_file sets credentials.password.
+const secretFileSuffix = "_file"
+
+// Secret is a sensitive config value. It's printed, logged and marshalled as
+// [redacted] so it can't leak through the config dump or the logs, Value
+// returns the actual value.
+type Secret string
+
+// Value returns the secret in plain text.
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return strconv.Quote(s.String())
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// EnvPrefix is the prefix of the environment variables overriding the config.
+const EnvPrefix = "EMAILER_"
+
+// EnvKey maps an EMAILER_ prefixed environment variable to its config key,
+// sections being separated by a double underscore, e.g.
+// EMAILER_CREDENTIALS__PASSWORD sets credentials.password.
+func EnvKey(name string) string {
+	name = strings.TrimPrefix(name, EnvPrefix)
+	return strings.ReplaceAll(strings.ToLower(name), "__", ".")
+}
+
+// LoadSecretFiles sets every key having a <key>_file counterpart to the
+// content of that file, without the trailing newline, e.g. to read the
+// password from a mounted Kubernetes or Docker secret.
+func LoadSecretFiles(k *koanf.Koanf) error {
+	secrets := make(map[string]interface{})
+	for _, key := range k.Keys() {
+		path := k.String(key)
+		if !strings.HasSuffix(key, secretFileSuffix) || path == "" {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %v", key, err)
+		}
+		secrets[strings.TrimSuffix(key, secretFileSuffix)] = strings.TrimRight(string(content), "\r\n")
+	}
+	return k.Load(confmap.Provider(secrets, "."), nil)
+}
+
+// Print prints the config, with the secrets redacted, as koanf's Print does.
+func (c Config) Print() {
+	k := koanf.New(".")
+	_ = k.Load(structs.Provider(c, "koanf"), nil)
+	k.Print()
+}
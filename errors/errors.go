@@ -48,6 +48,7 @@ const (
 	NotFound                 // Entity does not exist
 	Unauthorized             // Unauthorized access
 	Forbidden                // Forbidden access
+	Unavailable              // Temporarily unable to serve, e.g. a full queue
 )
 
 func (k Kind) String() string {
@@ -60,6 +61,8 @@ func (k Kind) String() string {
 		return "invalid input"
 	case NotFound:
 		return "entity not found"
+	case Unavailable:
+		return "service unavailable"
 	default:
 		return "unknown error kind"
 	}
@@ -86,7 +89,39 @@ func E(args ...interface{}) error {
 	return e
 }
 
+// IsPermanent reports whether retrying can't fix the error, i.e. the input
+// itself is invalid.
+func IsPermanent(err error) bool {
+	var e *Error
+	return As(err, &e) && e.Kind == Invalid
+}
+
+// KindOf returns the kind of the first application error in the chain of err,
+// or Other when there is none.
+func KindOf(err error) Kind {
+	var e *Error
+	if As(err, &e) {
+		return e.Kind
+	}
+	return Other
+}
+
+// Message returns the message of err, the wrapped error's for application
+// errors which otherwise print as JSON.
+func Message(err error) string {
+	var e *Error
+	switch {
+	case !As(err, &e):
+		return err.Error()
+	case e.WrappedErr != nil:
+		return e.WrappedErr.Error()
+	default:
+		return e.Message
+	}
+}
+
 var (
-	As = errors.As
-	Is = errors.Is
+	As   = errors.As
+	Is   = errors.Is
+	Join = errors.Join
 )
@@ -1,5 +1,11 @@
 package errors
 
+import (
+	// Go Internal Packages
+	"fmt"
+	"strings"
+)
+
 type FieldError struct {
 	Field string `json:"field"`
 	Error string `json:"error"`
@@ -15,6 +21,15 @@ func (v ValidationErrors) Error() string {
 	return "validation failed"
 }
 
+// Report lists the field errors one per line, for command line output.
+func (v ValidationErrors) Report() string {
+	lines := make([]string, len(v))
+	for i, fe := range v {
+		lines[i] = fmt.Sprintf("  %s: %s", fe.Field, fe.Error)
+	}
+	return strings.Join(lines, "\n")
+}
+
 func ValidationErrs() *ValidationErrorBuilder {
 	return &ValidationErrorBuilder{}
 }
@@ -27,6 +42,23 @@ func (b *ValidationErrorBuilder) Add(field, err string) {
 	b.ve = append(b.ve, FieldError{Field: field, Error: err})
 }
 
+// AddErr adds the field errors of err under the prefix, e.g. emails.2 for
+// emails.2.user.mail_id, or its message when it has no field errors.
+func (b *ValidationErrorBuilder) AddErr(prefix string, err error) {
+	var fields ValidationErrors
+	if !As(err, &fields) {
+		b.Add(prefix, Message(err))
+		return
+	}
+	for _, fe := range fields {
+		field := prefix
+		if fe.Field != "" {
+			field = prefix + "." + fe.Field
+		}
+		b.Add(field, fe.Error)
+	}
+}
+
 func (b *ValidationErrorBuilder) Err() error {
 	if len(b.ve) == 0 {
 		return nil
@@ -0,0 +1,92 @@
+package http
+
+import (
+	// Go Internal Packages
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	// Local Packages
+	errors "github.com/satya-ajayy/Emailer/errors"
+	apxresp "github.com/satya-ajayy/Emailer/http/response"
+	models "github.com/satya-ajayy/Emailer/models"
+	ingest "github.com/satya-ajayy/Emailer/services/ingest"
+)
+
+type batchRequest struct {
+	Emails          []json.RawMessage `json:"emails"`
+	IdempotencyKeys []string          `json:"idempotency_keys"`
+}
+
+type acceptedResponse struct {
+	MessageIDs []string `json:"message_ids"`
+}
+
+// SendEmailHandler validates and renders the email in the request body, shaped
+// as the Kafka records, and accepts it to be sent. The Idempotency-Key header,
+// when given, is used as its message ID so retried requests aren't sent twice.
+// Bodies over models.MaxEmailSize are rejected
+func (s *Server) SendEmailHandler(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, models.MaxEmailSize))
+	if err != nil {
+		respondBodyError(w, err)
+		return
+	}
+
+	id, err := s.ingest.Send(r.Context(), ingest.Email{Payload: body, IdempotencyKey: r.Header.Get("Idempotency-Key")})
+	if err != nil {
+		respondAppError(w, err)
+		return
+	}
+	apxresp.RespondJSON(w, http.StatusAccepted, acceptedResponse{MessageIDs: []string{id}})
+}
+
+// SendBatchHandler validates and renders every email of the batch and accepts
+// them all to be sent, or none of them when any is invalid. The validation
+// errors are reported per email, e.g. emails.2.user.mail_id. The optional
+// idempotency_keys, one per email, are used as their message IDs like the
+// Idempotency-Key header. Bodies over ingest.max_batch emails of
+// models.MaxEmailSize are rejected
+func (s *Server) SendBatchHandler(w http.ResponseWriter, r *http.Request) {
+	var req batchRequest
+	body := http.MaxBytesReader(w, r.Body, int64(s.ingest.MaxBatch())*models.MaxEmailSize)
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		respondBodyError(w, err)
+		return
+	}
+
+	if len(req.IdempotencyKeys) > 0 && len(req.IdempotencyKeys) != len(req.Emails) {
+		ve := errors.ValidationErrs()
+		ve.Add("idempotency_keys", fmt.Sprintf("must have one key per email, got %d for %d emails",
+			len(req.IdempotencyKeys), len(req.Emails)))
+		respondAppError(w, errors.ValidationFailedErr(ve.Err()))
+		return
+	}
+
+	emails := make([]ingest.Email, len(req.Emails))
+	for i, email := range req.Emails {
+		emails[i] = ingest.Email{Payload: email}
+		if len(req.IdempotencyKeys) > 0 {
+			emails[i].IdempotencyKey = req.IdempotencyKeys[i]
+		}
+	}
+	ids, err := s.ingest.SendBatch(r.Context(), emails)
+	if err != nil {
+		respondAppError(w, err)
+		return
+	}
+	apxresp.RespondJSON(w, http.StatusAccepted, acceptedResponse{MessageIDs: ids})
+}
+
+// respondBodyError responds 413 when the body was over its limit, and 400
+// when it couldn't be read otherwise.
+func respondBodyError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		apxresp.RespondMessage(w, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("request body cannot exceed %d bytes", tooLarge.Limit))
+		return
+	}
+	apxresp.RespondError(w, errors.InvalidBodyErr(err).(*errors.Error))
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	// Go Internal Packages
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	// Local Packages
+	config "github.com/satya-ajayy/Emailer/config"
+	metrics "github.com/satya-ajayy/Emailer/metrics"
+	models "github.com/satya-ajayy/Emailer/models"
+	ingest "github.com/satya-ajayy/Emailer/services/ingest"
+	processors "github.com/satya-ajayy/Emailer/services/processors"
+	templates "github.com/satya-ajayy/Emailer/templates"
+	alert "github.com/satya-ajayy/Emailer/utils/alert"
+	mailaddr "github.com/satya-ajayy/Emailer/utils/mailaddr"
+
+	// External Packages
+	"go.uber.org/zap"
+)
+
+type queued struct {
+	records []models.Record
+}
+
+func (q *queued) Enqueue(_ context.Context, records []models.Record) error {
+	q.records = append(q.records, records...)
+	return nil
+}
+
+func TestSendBatch(t *testing.T) {
+	logger := zap.NewNop()
+	registry, err := templates.NewRegistry(logger, config.Templates{Default: "problems", DefaultLocale: "en"},
+		alert.Discard)
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+	processor := processors.NewProcessor(logger, config.Credentials{MailID: "emailer@example.com"}, registry,
+		mailaddr.NewValidator(config.Addresses{}, nil), metrics.New("test"),
+		config.Idempotency{MaxKeys: 10, TTL: time.Hour}, config.Schedule{}, nil, false)
+
+	fixture, err := os.ReadFile("../templates/fixtures/problems/default.json")
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	tests := []struct {
+		name   string
+		keys   []string
+		status int
+		ids    []string
+	}{
+		{name: "keys as message IDs", keys: []string{"weekly-1", "weekly-2"}, status: http.StatusAccepted,
+			ids: []string{"weekly-1", "weekly-2"}},
+		{name: "one key short", keys: []string{"weekly-1"}, status: http.StatusBadRequest},
+		{name: "no keys", status: http.StatusAccepted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := &queued{}
+			s := &Server{processor: processor, templates: registry, ingest: ingest.NewService(logger, processor, queue,
+				config.Ingest{Mode: config.IngestDirect, MaxBatch: 2, StatusTTL: time.Hour, MaxStatuses: 10})}
+
+			body, err := json.Marshal(batchRequest{Emails: []json.RawMessage{fixture, fixture}, IdempotencyKeys: tt.keys})
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := httptest.NewRecorder()
+			s.SendBatchHandler(w, httptest.NewRequest(http.MethodPost, "/v1/emails/batch", bytes.NewReader(body)))
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status != http.StatusAccepted {
+				if len(queue.records) != 0 {
+					t.Errorf("queued %d records, want none", len(queue.records))
+				}
+				return
+			}
+
+			var resp acceptedResponse
+			if err = json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.MessageIDs) != 2 || len(queue.records) != 2 {
+				t.Fatalf("message IDs = %v, queued %d records, want 2", resp.MessageIDs, len(queue.records))
+			}
+			if tt.ids != nil && !reflect.DeepEqual(resp.MessageIDs, tt.ids) {
+				t.Errorf("message IDs = %v, want %v", resp.MessageIDs, tt.ids)
+			}
+		})
+	}
+}
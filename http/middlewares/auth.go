@@ -0,0 +1,38 @@
+package middlewares
+
+import (
+	// Go Internal Packages
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	// Local Packages
+	config "github.com/satya-ajayy/Emailer/config"
+	errors "github.com/satya-ajayy/Emailer/errors"
+	apxresp "github.com/satya-ajayy/Emailer/http/response"
+)
+
+// BearerAuth creates a middleware rejecting the requests without the token in
+// their Authorization header. An empty token lets every request through.
+func BearerAuth(token config.Secret) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token != "" && !ValidToken(r.Header.Get("Authorization"), token) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="emailer"`)
+				apxresp.RespondError(w, errors.E(errors.Unauthorized, "missing or invalid bearer token").(*errors.Error))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ValidToken reports whether the Authorization header value carries the
+// token as a bearer token, comparing them in constant time.
+func ValidToken(authorization string, token config.Secret) bool {
+	scheme, given, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(given)), []byte(token.Value())) == 1
+}
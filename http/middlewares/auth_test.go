@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	// Go Internal Packages
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	// Local Packages
+	config "github.com/satya-ajayy/Emailer/config"
+)
+
+func TestBearerAuth(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         config.Secret
+		authorization string
+		want          int
+	}{
+		{name: "valid token", token: "s3cret", authorization: "Bearer s3cret", want: http.StatusOK},
+		{name: "lowercase scheme", token: "s3cret", authorization: "bearer s3cret", want: http.StatusOK},
+		{name: "missing header", token: "s3cret", want: http.StatusUnauthorized},
+		{name: "wrong token", token: "s3cret", authorization: "Bearer other", want: http.StatusUnauthorized},
+		{name: "token prefix", token: "s3cret", authorization: "Bearer s3c", want: http.StatusUnauthorized},
+		{name: "basic scheme", token: "s3cret", authorization: "Basic s3cret", want: http.StatusUnauthorized},
+		{name: "no token configured", authorization: "", want: http.StatusOK},
+	}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/emailer/v1/emails", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			BearerAuth(tt.token)(ok).ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if challenge := rec.Header().Get("WWW-Authenticate"); (challenge != "") != (tt.want == http.StatusUnauthorized) {
+				t.Errorf("WWW-Authenticate = %q", challenge)
+			}
+		})
+	}
+}
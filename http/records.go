@@ -0,0 +1,70 @@
+package http
+
+import (
+	// Go Internal Packages
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	// Local Packages
+	errors "github.com/satya-ajayy/Emailer/errors"
+	apxresp "github.com/satya-ajayy/Emailer/http/response"
+	pipeline "github.com/satya-ajayy/Emailer/pipeline"
+
+	// External Packages
+	"github.com/go-chi/chi"
+	"go.uber.org/zap"
+)
+
+// SendRecordHandler reads the record at the topic, partition and offset in the
+// path from Kafka and processes it again, e.g. to send an email whose record
+// failed once the cause has been fixed. Slack alerts link to it
+func (s *Server) SendRecordHandler(w http.ResponseWriter, r *http.Request) {
+	topic := chi.URLParam(r, "topic")
+	ve := errors.ValidationErrs()
+	partition, err := strconv.ParseInt(chi.URLParam(r, "partition"), 10, 32)
+	if err != nil || partition < 0 {
+		ve.Add("partition", "must be a non negative integer")
+	}
+	offset, err := strconv.ParseInt(chi.URLParam(r, "offset"), 10, 64)
+	if err != nil || offset < 0 {
+		ve.Add("offset", "must be a non negative integer")
+	}
+	if ve.Len() > 0 {
+		apxresp.RespondError(w, errors.ValidationFailedErr(ve.Err()).(*errors.Error))
+		return
+	}
+
+	record, err := s.consumer.FetchRecord(r.Context(), topic, int32(partition), offset)
+	if err != nil {
+		s.logger.Error("failed to fetch record", zap.String("topic", topic), zap.Int64("partition", partition),
+			zap.Int64("offset", offset), zap.Error(err))
+		respondAppError(w, err)
+		return
+	}
+
+	err = s.processor.ProcessRecord(r.Context(), record)
+	var later *pipeline.Deferred
+	if errors.As(err, &later) {
+		msg := fmt.Sprintf("email is scheduled for %s, send it again then", later.Until.Format(time.RFC3339))
+		apxresp.RespondError(w, errors.E(errors.Unavailable, msg).(*errors.Error))
+		return
+	}
+	if err != nil {
+		s.logger.Error("failed to send record", zap.String("record", record.Position()), zap.Error(err))
+		respondAppError(w, err)
+		return
+	}
+	apxresp.RespondMessage(w, http.StatusOK, fmt.Sprintf("sent %s", record.Position()))
+}
+
+// respondAppError responds with the error, wrapping errors that aren't
+// application errors as internal ones.
+func respondAppError(w http.ResponseWriter, err error) {
+	var appErr *errors.Error
+	if !errors.As(err, &appErr) {
+		appErr = errors.E(errors.Internal, err.Error(), err).(*errors.Error)
+	}
+	apxresp.RespondError(w, appErr)
+}
@@ -6,7 +6,7 @@ import (
 	"net/http"
 
 	// Local Packages
-	errors "emailer/errors"
+	errors "github.com/satya-ajayy/Emailer/errors"
 )
 
 // RespondJSON writes the data to the response writer as JSON
@@ -46,6 +46,8 @@ func RespondError(w http.ResponseWriter, err *errors.Error) {
 		RespondMessage(w, http.StatusUnauthorized, err.Message)
 	case errors.Forbidden:
 		RespondMessage(w, http.StatusForbidden, err.Message)
+	case errors.Unavailable:
+		RespondMessage(w, http.StatusServiceUnavailable, err.Message)
 	default:
 		RespondMessage(w, http.StatusInternalServerError, err.Message)
 	}
@@ -3,66 +3,129 @@ package http
 import (
 	// Go Internal Packages
 	"context"
+	"net"
 	"net/http"
 	"time"
 
 	// Local Packages
-	smiddleware "emailer/http/middlewares"
-	apxresp "emailer/http/response"
-	kafka "emailer/kafka"
-	health "emailer/services/health"
+	config "github.com/satya-ajayy/Emailer/config"
+	errors "github.com/satya-ajayy/Emailer/errors"
+	smiddleware "github.com/satya-ajayy/Emailer/http/middlewares"
+	apxresp "github.com/satya-ajayy/Emailer/http/response"
+	kafka "github.com/satya-ajayy/Emailer/kafka"
+	metrics "github.com/satya-ajayy/Emailer/metrics"
+	health "github.com/satya-ajayy/Emailer/services/health"
+	ingest "github.com/satya-ajayy/Emailer/services/ingest"
+	processors "github.com/satya-ajayy/Emailer/services/processors"
+	templates "github.com/satya-ajayy/Emailer/templates"
 
 	// External Packages
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 	_ "github.com/jsternberg/zap-logfmt"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
 // Server struct follows the alphabet order
 type Server struct {
-	consumer *kafka.Consumer
-	health   *health.HealthCheckService
-	logger   *zap.Logger
-	prefix   string
+	auth      config.Auth
+	consumer  *kafka.Consumer
+	grpc      *grpc.Server
+	health    *health.HealthCheckService
+	ingest    *ingest.IngestService
+	logger    *zap.Logger
+	metrics   *metrics.Metrics
+	prefix    string
+	processor *processors.MailProcessor
+	templates *templates.Registry
 }
 
-func NewServer(prefix string, logger *zap.Logger, consumer *kafka.Consumer, healthCheck *health.HealthCheckService) *Server {
+func NewServer(prefix string, logger *zap.Logger, consumer *kafka.Consumer, healthCheck *health.HealthCheckService,
+	processor *processors.MailProcessor, registry *templates.Registry, metrics *metrics.Metrics,
+	ingest *ingest.IngestService, grpcServer *grpc.Server, auth config.Auth) *Server {
 	return &Server{
-		consumer: consumer,
-		logger:   logger,
-		prefix:   prefix,
-		health:   healthCheck,
+		auth:      auth,
+		consumer:  consumer,
+		grpc:      grpcServer,
+		ingest:    ingest,
+		logger:    logger,
+		metrics:   metrics,
+		prefix:    prefix,
+		health:    healthCheck,
+		processor: processor,
+		templates: registry,
 	}
 }
 
-func (s *Server) Listen(ctx context.Context, addr string) error {
+// Listen serves the HTTP API on addr and the gRPC API on grpcAddr until the
+// context is done or either fails.
+func (s *Server) Listen(ctx context.Context, addr, grpcAddr string) error {
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
 	r.Use(smiddleware.HTTPMiddleware(s.logger))
 	r.Use(middleware.Recoverer)
 
+	r.Handle("/metrics", s.metrics.Handler())
 	r.Route(s.prefix, func(r chi.Router) {
 		r.Route("/v1", func(r chi.Router) {
 			r.Get("/health", s.HealthCheckHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(smiddleware.BearerAuth(s.auth.APIToken))
+				r.Post("/emails", s.SendEmailHandler)
+				r.Post("/emails/batch", s.SendBatchHandler)
+			})
+			r.Route("/templates/{name}", func(r chi.Router) {
+				r.Post("/render", s.RenderTemplateHandler)
+				r.Get("/preview", s.PreviewTemplateHandler)
+			})
+			r.Route("/admin", func(r chi.Router) {
+				r.Use(smiddleware.BearerAuth(s.auth.AdminToken))
+				r.Post("/templates/reload", s.ReloadTemplatesHandler)
+				r.Post("/records/{topic}/{partition}/{offset}/send", s.SendRecordHandler)
+			})
 		})
 	})
 
-	errch := make(chan error)
+	lis, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		return err
+	}
+
+	errch := make(chan error, 2)
 	server := &http.Server{Addr: addr, Handler: r}
 	go func() {
 		s.logger.Info("starting server", zap.String("addr", addr))
 		errch <- server.ListenAndServe()
 	}()
+	go func() {
+		s.logger.Info("starting grpc server", zap.String("addr", grpcAddr))
+		errch <- s.grpc.Serve(lis)
+	}()
 
 	select {
-	case err := <-errch:
+	case err = <-errch:
+		s.grpc.Stop()
+		_ = server.Close()
 		return err
 	case <-ctx.Done():
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
-		return server.Shutdown(shutdownCtx)
+		stopped := make(chan struct{})
+		go func() {
+			s.grpc.GracefulStop()
+			close(stopped)
+		}()
+		err = server.Shutdown(shutdownCtx)
+
+		// streams such as WatchStatus may outlive the timeout
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			s.grpc.Stop()
+		}
+		return err
 	}
 }
 
@@ -74,3 +137,16 @@ func (s *Server) HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	apxresp.RespondMessage(w, http.StatusOK, "!!! We are RunninGoo !!!")
 }
+
+// ReloadTemplatesHandler reparses the templates from the templates directory
+func (s *Server) ReloadTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	if err := s.templates.Reload(); err != nil {
+		s.logger.Error("failed to reload templates", zap.Error(err))
+		apxresp.RespondError(w, errors.E(errors.Invalid, "template reload failed", err).(*errors.Error))
+		return
+	}
+	apxresp.RespondJSON(w, http.StatusOK, map[string]any{
+		"message":   "templates reloaded",
+		"templates": s.templates.Names(),
+	})
+}
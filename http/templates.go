@@ -0,0 +1,103 @@
+package http
+
+import (
+	// Go Internal Packages
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+
+	// Local Packages
+	errors "github.com/satya-ajayy/Emailer/errors"
+	apxresp "github.com/satya-ajayy/Emailer/http/response"
+	models "github.com/satya-ajayy/Emailer/models"
+
+	// External Packages
+	"github.com/go-chi/chi"
+)
+
+// defaultFixture is the sample data previewed when no fixture is requested.
+const defaultFixture = "default"
+
+// RenderTemplateHandler renders the template with the data in the request
+// body and returns the composed email without sending it. Bodies over
+// models.MaxEmailSize are rejected
+func (s *Server) RenderTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if !s.templates.Has(name) {
+		apxresp.RespondError(w, errors.E(errors.NotFound, fmt.Sprintf("template %q not found", name)).(*errors.Error))
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, models.MaxEmailSize))
+	if err != nil {
+		respondBodyError(w, err)
+		return
+	}
+
+	var data models.UserLinks
+	if err = json.Unmarshal(body, &data); err != nil {
+		apxresp.RespondError(w, errors.InvalidBodyErr(err).(*errors.Error))
+		return
+	}
+	if err = s.templates.Validate(name, body); err != nil {
+		respondAppError(w, err)
+		return
+	}
+
+	s.respondRendered(w, r, name, data)
+}
+
+// PreviewTemplateHandler renders the template with one of its stored sample
+// fixtures. The HTML is returned so it can be viewed in a browser, pass
+// format=json to get the whole composed email instead
+func (s *Server) PreviewTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if !s.templates.Has(name) {
+		apxresp.RespondError(w, errors.E(errors.NotFound, fmt.Sprintf("template %q not found", name)).(*errors.Error))
+		return
+	}
+
+	fixture := r.URL.Query().Get("fixture")
+	if fixture == "" {
+		fixture = defaultFixture
+	}
+
+	content, err := s.templates.Fixture(name, fixture)
+	if errors.Is(err, fs.ErrNotExist) {
+		apxresp.RespondError(w, errors.E(errors.NotFound, fmt.Sprintf("fixture %q not found", fixture)).(*errors.Error))
+		return
+	}
+	if err != nil {
+		apxresp.RespondError(w, errors.E(errors.Invalid, "invalid fixture", err).(*errors.Error))
+		return
+	}
+
+	var data models.UserLinks
+	if err = json.Unmarshal(content, &data); err != nil {
+		apxresp.RespondError(w, errors.E(errors.Invalid, "invalid fixture", err).(*errors.Error))
+		return
+	}
+
+	s.respondRendered(w, r, name, data)
+}
+
+func (s *Server) respondRendered(w http.ResponseWriter, r *http.Request, name string, data models.UserLinks) {
+	data.Template = name
+	email, err := s.processor.Compose(data)
+	if err != nil {
+		apxresp.RespondError(w, errors.E(errors.Invalid, "template render failed", err).(*errors.Error))
+		return
+	}
+
+	if r.Method == http.MethodGet && r.URL.Query().Get("format") != "json" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("X-Email-Subject", mime.QEncoding.Encode("utf-8", email.Subject))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(email.HTML))
+		return
+	}
+	apxresp.RespondJSON(w, http.StatusOK, email)
+}
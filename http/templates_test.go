@@ -0,0 +1,72 @@
+package http
+
+import (
+	// Go Internal Packages
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	// Local Packages
+	config "github.com/satya-ajayy/Emailer/config"
+	metrics "github.com/satya-ajayy/Emailer/metrics"
+	models "github.com/satya-ajayy/Emailer/models"
+	processors "github.com/satya-ajayy/Emailer/services/processors"
+	templates "github.com/satya-ajayy/Emailer/templates"
+	alert "github.com/satya-ajayy/Emailer/utils/alert"
+	mailaddr "github.com/satya-ajayy/Emailer/utils/mailaddr"
+
+	// External Packages
+	"github.com/go-chi/chi"
+	"go.uber.org/zap"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	logger := zap.NewNop()
+	registry, err := templates.NewRegistry(logger, config.Templates{Default: "problems", DefaultLocale: "en"},
+		alert.Discard)
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+	processor := processors.NewProcessor(logger, config.Credentials{MailID: "emailer@example.com"}, registry,
+		mailaddr.NewValidator(config.Addresses{}, nil), metrics.New("test"),
+		config.Idempotency{MaxKeys: 10, TTL: time.Hour}, config.Schedule{}, nil, false)
+	s := &Server{processor: processor, templates: registry}
+	router := chi.NewRouter()
+	router.Post("/templates/{name}/render", s.RenderTemplateHandler)
+
+	fixture, err := os.ReadFile("../templates/fixtures/problems/default.json")
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	tests := []struct {
+		name     string
+		template string
+		body     []byte
+		status   int
+	}{
+		{name: "fixture", template: "problems", body: fixture, status: http.StatusOK},
+		{name: "unknown template", template: "nope", body: fixture, status: http.StatusNotFound},
+		{name: "invalid JSON", template: "problems", body: []byte(`{`), status: http.StatusBadRequest},
+		{
+			name:     "over the limit",
+			template: "problems",
+			body:     []byte(`{"user":"` + strings.Repeat("x", models.MaxEmailSize) + `"}`),
+			status:   http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/templates/"+tt.template+"/render", bytes.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+}
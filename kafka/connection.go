@@ -0,0 +1,87 @@
+package kafka
+
+import (
+	// Go Internal Packages
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	// Local Packages
+	config "github.com/satya-ajayy/Emailer/config"
+
+	// External Packages
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl"
+	"github.com/twmb/franz-go/pkg/sasl/oauth"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
+)
+
+// ConnectionOpts returns the options connecting a kgo client to the brokers:
+// the seed brokers, client ID, TLS and SASL.
+func ConnectionOpts(conf config.Kafka) ([]kgo.Opt, error) {
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(conf.Brokers...), // Connects to Kafka brokers
+		kgo.ClientID(conf.ClientID),      // Identifies the emailer to the brokers
+	}
+
+	if conf.TLS.Enabled {
+		tlsConf, err := tlsConfig(conf.TLS)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, kgo.DialTLSConfig(tlsConf))
+	}
+
+	if conf.SASL.Mechanism != "" {
+		mechanism, err := saslMechanism(conf.SASL)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, kgo.SASL(mechanism))
+	}
+	return opts, nil
+}
+
+// tlsConfig builds the TLS config trusting the CA, when set, and presenting
+// the client certificate, when set.
+func tlsConfig(conf config.KafkaTLS) (*tls.Config, error) {
+	tlsConf := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: conf.ServerName,
+	}
+
+	if conf.CA != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(conf.CA)) {
+			return nil, errors.New("kafka tls ca contains no PEM certificates")
+		}
+		tlsConf.RootCAs = pool
+	}
+
+	if conf.Cert != "" {
+		cert, err := tls.X509KeyPair([]byte(conf.Cert), []byte(conf.Key.Value()))
+		if err != nil {
+			return nil, fmt.Errorf("error loading kafka tls client certificate: %v", err)
+		}
+		tlsConf.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConf, nil
+}
+
+// saslMechanism returns the SASL mechanism authenticating the emailer.
+func saslMechanism(conf config.KafkaSASL) (sasl.Mechanism, error) {
+	switch conf.Mechanism {
+	case config.SASLPlain:
+		return plain.Auth{User: conf.Username, Pass: conf.Password.Value()}.AsMechanism(), nil
+	case config.SASLScramSHA256:
+		return scram.Auth{User: conf.Username, Pass: conf.Password.Value()}.AsSha256Mechanism(), nil
+	case config.SASLScramSHA512:
+		return scram.Auth{User: conf.Username, Pass: conf.Password.Value()}.AsSha512Mechanism(), nil
+	case config.SASLOAuthBearer:
+		return oauth.Auth{Token: conf.Token.Value()}.AsMechanism(), nil
+	default:
+		return nil, fmt.Errorf("unsupported kafka sasl mechanism %q", conf.Mechanism)
+	}
+}
@@ -5,111 +5,306 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	// Local Packages
-	models "emailer/models"
-	slack "emailer/utils/slack"
+	apperrors "github.com/satya-ajayy/Emailer/errors"
+	metrics "github.com/satya-ajayy/Emailer/metrics"
+	models "github.com/satya-ajayy/Emailer/models"
+	pipeline "github.com/satya-ajayy/Emailer/pipeline"
+	tracing "github.com/satya-ajayy/Emailer/tracing"
 
 	// External Packages
+	"github.com/twmb/franz-go/pkg/kerr"
 	"github.com/twmb/franz-go/pkg/kgo"
-	"github.com/twmb/franz-go/plugin/kprom"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
+var tracer = tracing.Tracer("github.com/satya-ajayy/Emailer/kafka")
+
+// fetchTimeout bounds FetchRecord, which otherwise waits for offsets that
+// haven't been produced yet.
+const fetchTimeout = 10 * time.Second
+
+// Consumer is the pipeline Source consuming the mails from Kafka, the records
+// of a batch are committed to the consumer group once processed. It's a
+// pipeline Holder, the offsets of a partition are committed up to its first
+// held record.
 type Consumer struct {
-	client    *kgo.Client
-	config    *models.ConsumerConfig
-	processor MailProcessor
-	logger    *zap.Logger
-	slack     slack.Sender
+	client *kgo.Client
+	config atomic.Pointer[models.ConsumerConfig]
+	// connection are the options connecting to the brokers, reused by
+	// FetchRecord
+	connection []kgo.Opt
+	metrics    *metrics.Metrics
+	logger     *zap.Logger
+	// pending are the records returned by the last Next, waiting for Commit
+	pending []*kgo.Record
+
+	mu sync.Mutex
+	// next are the offsets after the last processed record of the partitions
+	next map[topicPartition]int64
+	// held are the held records of the partitions
+	held map[topicPartition]*held
+	// released is set when records were released since the last commit
+	released bool
+}
+
+type topicPartition struct {
+	topic     string
+	partition int32
 }
 
-type MailProcessor interface {
-	ProcessRecord(ctx context.Context, record models.Record) error
+// held are the held offsets of a partition, handedOver is closed when the
+// partition is revoked.
+type held struct {
+	offsets    map[int64]bool
+	handedOver chan struct{}
 }
 
 // NewConsumer creates a new consumer to consume mails
-// (PS: Must call Poll to start consuming the records)
-func NewConsumer(conf *models.ConsumerConfig, processor MailProcessor, metrics *kprom.Metrics, logger *zap.Logger, slack slack.Sender) (*Consumer, error) {
+// (PS: Must be run by a pipeline to start consuming the records)
+// The connection options come from ConnectionOpts.
+func NewConsumer(conf *models.ConsumerConfig, connection []kgo.Opt, metrics *metrics.Metrics,
+	logger *zap.Logger) (*Consumer, error) {
 	c := &Consumer{
-		config:    conf,
-		processor: processor,
-		logger:    logger,
-		slack:     slack,
+		metrics:    metrics,
+		logger:     logger,
+		connection: connection,
+		next:       make(map[topicPartition]int64),
+		held:       make(map[topicPartition]*held),
 	}
 
-	opts := []kgo.Opt{
-		kgo.SeedBrokers(conf.Brokers...), // Connects to Kafka brokers
-		kgo.ConsumerGroup(conf.Name),     // Specifies the consumer group
-		kgo.ConsumeTopics(conf.Topic),    // Specifies a single topic to consume
-		kgo.WithHooks(metrics),           // Attaches monitoring hooks
-		kgo.DisableAutoCommit(),          // Disables auto-commit
-		kgo.BlockRebalanceOnPoll(),       // Blocks rebalancing until the poll loop is running
+	opts := append(slices.Clip(connection),
+		kgo.ConsumerGroup(conf.Name),                // Specifies the consumer group
+		kgo.ConsumeTopics(conf.Topics...),           // Specifies the topics to consume
+		kgo.SessionTimeout(conf.SessionTimeout),     // Evicts members that stop heartbeating
+		kgo.RebalanceTimeout(conf.RebalanceTimeout), // Bounds how long members may take to rejoin
+		kgo.WithHooks(metrics.Kafka),                // Attaches monitoring hooks
+		kgo.DisableAutoCommit(),                     // Disables auto-commit
+		kgo.BlockRebalanceOnPoll(),                  // Blocks rebalancing until the poll loop is running
+		kgo.OnPartitionsRevoked(c.handOver),         // Drops the held records of the revoked partitions
+		kgo.OnPartitionsLost(c.handOver),            // and of the lost ones
+	)
+	if conf.Regex {
+		opts = append(opts, kgo.ConsumeRegex()) // Consumes every topic matching the topics as regexes
+	}
+	if conf.Rack != "" {
+		opts = append(opts, kgo.Rack(conf.Rack)) // Fetches from the closest replicas
 	}
 
 	client, err := kgo.NewClient(opts...)
 	if err != nil || client == nil {
 		return nil, err
 	}
+	c.config.Store(conf)
 
 	c.client = client
 	return c, nil
 }
 
-// Poll polls for records from the Kafka broker.
-func (c *Consumer) Poll(ctx context.Context) error {
-	defer c.client.Close()
+// Apply switches the records per poll for the next polls. The connection,
+// group and topics can't be changed without a restart.
+func (c *Consumer) Apply(conf *models.ConsumerConfig) {
+	current := *c.config.Load()
+	current.RecordsPerPoll = conf.RecordsPerPoll
+	c.config.Store(&current)
+}
 
-	for {
-		// Check if the context is canceled before polling
-		if ctx.Err() != nil {
-			c.logger.Warn("polling stopped: context canceled")
-			return ctx.Err() // Exit gracefully
-		}
+// Next polls for records from the Kafka broker.
+func (c *Consumer) Next(ctx context.Context) (pipeline.Batch, error) {
+	conf := c.config.Load()
+	c.logger.Info(fmt.Sprintf("%s: polling for records", conf.Name))
+	pollCtx, pollSpan := tracer.Start(ctx, "kafka.poll")
+	fetches := c.client.PollRecords(pollCtx, conf.RecordsPerPoll)
+	pollSpan.SetAttributes(attribute.Int("messaging.batch.message_count", len(fetches.Records())))
+	pollSpan.End()
 
-		c.logger.Info(fmt.Sprintf("%s: polling for records", c.config.Name))
-		fetches := c.client.PollRecords(ctx, c.config.RecordsPerPoll)
+	// Handle client shutdown
+	if fetches.IsClientClosed() {
+		return pipeline.Batch{}, errors.New("kafka client closed")
+	}
 
-		// Handle client shutdown
-		if fetches.IsClientClosed() {
-			return errors.New("kafka client closed")
-		}
+	// Handle context cancellation explicitly
+	if errors.Is(fetches.Err0(), context.Canceled) {
+		return pipeline.Batch{}, errors.New("context got canceled")
+	}
 
-		// Handle context cancellation explicitly
-		if errors.Is(fetches.Err0(), context.Canceled) {
-			return errors.New("context got canceled")
-		}
+	// Preallocate records slice
+	c.pending = fetches.Records()
+	records := make([]models.Record, len(c.pending))
+	for idx, record := range c.pending {
+		records[idx] = toRecord(record)
+	}
 
-		// Preallocate records slice
-		records := make([]models.Record, len(fetches.Records()))
-		for idx, record := range fetches.Records() {
-			records[idx] = models.Record{
-				Key:   record.Key,
-				Value: record.Value,
-				Topic: record.Topic,
-			}
+	fetches.EachPartition(func(p kgo.FetchTopicPartition) {
+		if len(p.Records) > 0 {
+			last := p.Records[len(p.Records)-1].Offset
+			c.metrics.SetLag(p.Topic, p.Partition, p.HighWatermark-last-1)
 		}
+	})
 
-		total := len(records)
-		success := total
-		for _, record := range records {
-			err := c.processor.ProcessRecord(ctx, record)
-			if err != nil {
-				c.logger.Error("failed to process record", zap.Error(err))
-				if err = c.slack.SendAlert(record, err); err != nil {
-					c.logger.Error("failed to send slack message", zap.Error(err))
+	return pipeline.Batch{Records: records, Poll: pollSpan.SpanContext()}, nil
+}
+
+// Commit commits the records of the last poll, up to the first held record of
+// every partition, and lets the group rebalance.
+func (c *Consumer) Commit(ctx context.Context, batch pipeline.Batch) error {
+	defer c.client.AllowRebalance()
+
+	records := c.pending
+	c.pending = nil
+	offsets := c.offsets(records)
+	if len(offsets) == 0 {
+		return nil
+	}
+
+	commitCtx, commitSpan := tracer.Start(ctx, "kafka.commit", trace.WithLinks(trace.Link{SpanContext: batch.Poll}))
+	var err error
+	c.client.CommitOffsetsSync(commitCtx, offsets,
+		func(_ *kgo.Client, _ *kmsg.OffsetCommitRequest, resp *kmsg.OffsetCommitResponse, commitErr error) {
+			if commitErr != nil {
+				err = commitErr
+				return
+			}
+			for _, topic := range resp.Topics {
+				for _, partition := range topic.Partitions {
+					if err == nil {
+						err = kerr.ErrorForCode(partition.ErrorCode)
+					}
 				}
-				success = success - 1
 			}
+		})
+	tracing.End(commitSpan, err)
+	return err
+}
+
+// offsets returns the offsets to commit once the records are processed, none
+// when neither records were processed nor released since the last commit.
+func (c *Consumer) offsets(records []*kgo.Record) map[string]map[int32]kgo.EpochOffset {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(records) == 0 && !c.released {
+		return nil
+	}
+	c.released = false
+	for _, record := range records {
+		tp := topicPartition{topic: record.Topic, partition: record.Partition}
+		c.next[tp] = max(c.next[tp], record.Offset+1)
+	}
+
+	offsets := make(map[string]map[int32]kgo.EpochOffset)
+	for tp, next := range c.next {
+		if h, ok := c.held[tp]; ok {
+			for offset := range h.offsets {
+				next = min(next, offset)
+			}
+		}
+		if offsets[tp.topic] == nil {
+			offsets[tp.topic] = make(map[int32]kgo.EpochOffset)
 		}
+		offsets[tp.topic][tp.partition] = kgo.EpochOffset{Epoch: -1, Offset: next}
+	}
+	return offsets
+}
+
+// Hold keeps the offset of the record uncommitted until Release, so it's
+// consumed again after a restart.
+func (c *Consumer) Hold(record models.Record) <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tp := topicPartition{topic: record.Topic, partition: record.Partition}
+	h, ok := c.held[tp]
+	if !ok {
+		h = &held{offsets: make(map[int64]bool), handedOver: make(chan struct{})}
+		c.held[tp] = h
+	}
+	h.offsets[record.Offset] = true
+	return h.handedOver
+}
+
+// Release lets the offset of the record be committed with the next batch.
+func (c *Consumer) Release(record models.Record) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-		// Commit successfully processed records
-		c.logger.Info("processed records", zap.Int("success", success), zap.Int("failed", total-success))
-		if err := c.client.CommitRecords(ctx, fetches.Records()...); err != nil {
-			c.logger.Error("failed to commit processed records", zap.Error(err))
+	tp := topicPartition{topic: record.Topic, partition: record.Partition}
+	h, ok := c.held[tp]
+	if !ok || !h.offsets[record.Offset] {
+		return
+	}
+	delete(h.offsets, record.Offset)
+	if len(h.offsets) == 0 {
+		delete(c.held, tp)
+	}
+	c.released = true
+}
+
+// handOver forgets the revoked partitions, their new consumer starts from
+// their first held record.
+func (c *Consumer) handOver(_ context.Context, _ *kgo.Client, revoked map[string][]int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for topic, partitions := range revoked {
+		for _, partition := range partitions {
+			tp := topicPartition{topic: topic, partition: partition}
+			if h, ok := c.held[tp]; ok {
+				close(h.handedOver)
+				delete(c.held, tp)
+			}
+			delete(c.next, tp)
 		}
+	}
+}
 
-		c.client.AllowRebalance()
+// Close leaves the consumer group and closes the kgo client.
+func (c *Consumer) Close() error {
+	c.client.Close()
+	return nil
+}
+
+// FetchRecord reads the record at the given offset with a short lived client
+// outside of the consumer group, so it can be processed again.
+func (c *Consumer) FetchRecord(ctx context.Context, topic string, partition int32, offset int64) (models.Record, error) {
+	client, err := kgo.NewClient(append(slices.Clip(c.connection),
+		kgo.ConsumePartitions(map[string]map[int32]kgo.Offset{
+			topic: {partition: kgo.NewOffset().At(offset)},
+		}),
+	)...)
+	if err != nil {
+		return models.Record{}, fmt.Errorf("error creating kafka client: %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	for {
+		fetches := client.PollRecords(ctx, 1)
+		if err := ctx.Err(); err != nil {
+			return models.Record{}, apperrors.E(apperrors.NotFound, "record not found", err)
+		}
+		if errs := fetches.Errors(); len(errs) > 0 {
+			return models.Record{}, fmt.Errorf("error fetching record: %v", errs[0].Err)
+		}
+		for _, record := range fetches.Records() {
+			if record.Offset == offset {
+				return toRecord(record), nil
+			}
+			if record.Offset > offset {
+				// the record was compacted away or deleted by retention
+				return models.Record{}, apperrors.E(apperrors.NotFound, "record not found")
+			}
+		}
 	}
 }
 
@@ -117,3 +312,20 @@ func (c *Consumer) Poll(ctx context.Context) error {
 func (c *Consumer) Ping(ctx context.Context) error {
 	return c.client.Ping(ctx)
 }
+
+// toRecord copies a kgo record with its metadata and headers.
+func toRecord(record *kgo.Record) models.Record {
+	headers := make([]models.RecordHeader, len(record.Headers))
+	for i, h := range record.Headers {
+		headers[i] = models.RecordHeader{Key: h.Key, Value: h.Value}
+	}
+	return models.Record{
+		Key:       record.Key,
+		Value:     record.Value,
+		Topic:     record.Topic,
+		Partition: record.Partition,
+		Offset:    record.Offset,
+		Timestamp: record.Timestamp,
+		Headers:   headers,
+	}
+}
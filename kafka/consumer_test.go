@@ -0,0 +1,97 @@
+package kafka
+
+import (
+	// Go Internal Packages
+	"context"
+	"reflect"
+	"testing"
+
+	// Local Packages
+	models "github.com/satya-ajayy/Emailer/models"
+
+	// External Packages
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func newTestConsumer() *Consumer {
+	return &Consumer{next: make(map[topicPartition]int64), held: make(map[topicPartition]*held)}
+}
+
+func polled(partition int32, offsets ...int64) []*kgo.Record {
+	records := make([]*kgo.Record, len(offsets))
+	for i, offset := range offsets {
+		records[i] = &kgo.Record{Topic: "emails", Partition: partition, Offset: offset}
+	}
+	return records
+}
+
+func record(partition int32, offset int64) models.Record {
+	return models.Record{Topic: "emails", Partition: partition, Offset: offset}
+}
+
+// committed flattens the offsets to commit by partition.
+func committed(offsets map[string]map[int32]kgo.EpochOffset) map[int32]int64 {
+	if offsets == nil {
+		return nil
+	}
+	flat := make(map[int32]int64)
+	for partition, offset := range offsets["emails"] {
+		flat[partition] = offset.Offset
+	}
+	return flat
+}
+
+func TestCommitOffsets(t *testing.T) {
+	c := newTestConsumer()
+
+	// a held record holds back its partition only
+	handedOver := c.Hold(record(0, 11))
+	got := committed(c.offsets(append(polled(0, 10, 11, 12), polled(1, 5)...)))
+	if want := map[int32]int64{0: 11, 1: 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("committed %v, want %v", got, want)
+	}
+
+	// nothing to commit without new or released records
+	if got = committed(c.offsets(nil)); got != nil {
+		t.Errorf("committed %v without new records", got)
+	}
+
+	// once released, the partition moves on even without new records
+	c.Release(record(0, 11))
+	got = committed(c.offsets(nil))
+	if want := map[int32]int64{0: 13, 1: 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("committed %v after the release, want %v", got, want)
+	}
+	select {
+	case <-handedOver:
+		t.Error("released record handed over")
+	default:
+	}
+}
+
+func TestHandOver(t *testing.T) {
+	c := newTestConsumer()
+	revoked := c.Hold(record(0, 3))
+	kept := c.Hold(record(1, 7))
+	c.offsets(append(polled(0, 3, 4), polled(1, 7, 8)...))
+
+	c.handOver(context.Background(), nil, map[string][]int32{"emails": {0}})
+	select {
+	case <-revoked:
+	default:
+		t.Error("record of the revoked partition not handed over")
+	}
+	select {
+	case <-kept:
+		t.Error("record of a kept partition handed over")
+	default:
+	}
+
+	// the revoked partition isn't committed anymore, releasing its records
+	// does nothing
+	c.Release(record(0, 3))
+	got := committed(c.offsets(polled(1, 9)))
+	if want := map[int32]int64{1: 7}; !reflect.DeepEqual(got, want) {
+		t.Errorf("committed %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,74 @@
+package kafka
+
+import (
+	// Go Internal Packages
+	"context"
+	"fmt"
+	"slices"
+
+	// Local Packages
+	metrics "github.com/satya-ajayy/Emailer/metrics"
+	models "github.com/satya-ajayy/Emailer/models"
+	tracing "github.com/satya-ajayy/Emailer/tracing"
+
+	// External Packages
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Producer produces the emails received over HTTP to the topic consumed by
+// the emailers.
+type Producer struct {
+	client *kgo.Client
+	topic  string
+}
+
+// NewProducer creates a new producer to the topic, the connection options
+// come from ConnectionOpts.
+func NewProducer(connection []kgo.Opt, topic string, metrics *metrics.Metrics) (*Producer, error) {
+	client, err := kgo.NewClient(append(slices.Clip(connection),
+		kgo.DefaultProduceTopic(topic),
+		kgo.WithHooks(metrics.Kafka),
+	)...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating kafka producer: %v", err)
+	}
+	return &Producer{client: client, topic: topic}, nil
+}
+
+// Enqueue produces the records and waits for the brokers to acknowledge them.
+func (p *Producer) Enqueue(ctx context.Context, records []models.Record) (err error) {
+	ctx, span := tracer.Start(ctx, "kafka.produce", trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.destination.name", p.topic),
+			attribute.Int("messaging.batch.message_count", len(records)),
+		),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	produce := make([]*kgo.Record, len(records))
+	for i, record := range records {
+		tracing.Inject(ctx, &record)
+		produce[i] = p.fromRecord(record)
+	}
+	if err = p.client.ProduceSync(ctx, produce...).FirstErr(); err != nil {
+		return fmt.Errorf("error producing records: %v", err)
+	}
+	return nil
+}
+
+// Close flushes and closes the kgo client.
+func (p *Producer) Close() {
+	p.client.Close()
+}
+
+// fromRecord copies a record with its key, value and headers into a kgo
+// record of the producer's topic.
+func (p *Producer) fromRecord(record models.Record) *kgo.Record {
+	headers := make([]kgo.RecordHeader, len(record.Headers))
+	for i, h := range record.Headers {
+		headers[i] = kgo.RecordHeader{Key: h.Key, Value: h.Value}
+	}
+	return &kgo.Record{Key: record.Key, Value: record.Value, Topic: p.topic, Headers: headers}
+}
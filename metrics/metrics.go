@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	// Go Internal Packages
+	"net/http"
+	"strconv"
+	"time"
+
+	// Local Packages
+	errors "github.com/satya-ajayy/Emailer/errors"
+
+	// External Packages
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/twmb/franz-go/plugin/kprom"
+)
+
+// Metrics holds the franz-go client metrics and the processor metrics, all
+// registered on the same registry served at /metrics.
+type Metrics struct {
+	registry       *prometheus.Registry
+	Kafka          *kprom.Metrics
+	emailsSent     *prometheus.CounterVec
+	emailsFailed   *prometheus.CounterVec
+	renderDuration *prometheus.HistogramVec
+	smtpDuration   prometheus.Histogram
+	batchSize      prometheus.Histogram
+	skipped        *prometheus.CounterVec
+	consumerLag    *prometheus.GaugeVec
+	routeEmails    *prometheus.CounterVec
+}
+
+// New creates the metrics under the given namespace and registers them.
+func New(namespace string) *Metrics {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	m := &Metrics{
+		registry: registry,
+		Kafka:    kprom.NewMetrics(namespace, kprom.Registry(registry)),
+		emailsSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "emails_sent_total",
+			Help:      "Emails sent, by template.",
+		}, []string{"template"}),
+		emailsFailed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "emails_failed_total",
+			Help:      "Failed attempts to send an email, by template and error kind.",
+		}, []string{"template", "kind"}),
+		renderDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "render_duration_seconds",
+			Help:      "Time taken to render an email, by template.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25},
+		}, []string{"template"}),
+		smtpDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "smtp_duration_seconds",
+			Help:      "Time taken to dial the SMTP server and send an email.",
+			Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}),
+		batchSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "batch_size",
+			Help:      "Records returned by each poll.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 10),
+		}),
+		skipped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "records_skipped_total",
+			Help:      "Records that were not sent and won't be retried, by reason.",
+		}, []string{"reason"}),
+		consumerLag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "consumer_lag",
+			Help:      "Records between the last consumed offset and the high watermark, by partition.",
+		}, []string{"topic", "partition"}),
+		routeEmails: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "route_emails_total",
+			Help:      "Attempts to send the emails of each route, by route and result (sent, failed or duplicate).",
+		}, []string{"route", "result"}),
+	}
+
+	registry.MustRegister(m.emailsSent, m.emailsFailed, m.renderDuration, m.smtpDuration,
+		m.batchSize, m.skipped, m.consumerLag, m.routeEmails)
+	return m
+}
+
+// Handler returns the HTTP handler exposing all the metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// EmailSent counts an email sent with the template.
+func (m *Metrics) EmailSent(template string) {
+	m.emailsSent.WithLabelValues(template).Inc()
+}
+
+// EmailFailed counts a failed attempt to send an email with the template,
+// labelled with the kind of the error.
+func (m *Metrics) EmailFailed(template string, err error) {
+	kind := errors.Other
+	var e *errors.Error
+	if errors.As(err, &e) {
+		kind = e.Kind
+	}
+	m.emailsFailed.WithLabelValues(template, kind.String()).Inc()
+}
+
+// ObserveRender records the time taken to render the template.
+func (m *Metrics) ObserveRender(template string, d time.Duration) {
+	m.renderDuration.WithLabelValues(template).Observe(d.Seconds())
+}
+
+// ObserveSMTP records the time taken to send an email over SMTP.
+func (m *Metrics) ObserveSMTP(d time.Duration) {
+	m.smtpDuration.Observe(d.Seconds())
+}
+
+// ObserveBatch records the number of records returned by a poll.
+func (m *Metrics) ObserveBatch(size int) {
+	m.batchSize.Observe(float64(size))
+}
+
+// Skipped counts a record dropped for the given reason.
+func (m *Metrics) Skipped(reason string) {
+	m.skipped.WithLabelValues(reason).Inc()
+}
+
+// SetLag sets the consumer lag of the partition.
+func (m *Metrics) SetLag(topic string, partition int32, lag int64) {
+	m.consumerLag.WithLabelValues(topic, strconv.Itoa(int(partition))).Set(float64(lag))
+}
+
+// RouteEmail counts an attempt to send an email of the route with its result.
+func (m *Metrics) RouteEmail(route, result string) {
+	m.routeEmails.WithLabelValues(route, result).Inc()
+}
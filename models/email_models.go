@@ -0,0 +1,66 @@
+package models
+
+import (
+	// Go Internal Packages
+	"time"
+)
+
+// Email is a composed email ready to be handed to the mail transport.
+type Email struct {
+	From      string              `json:"from"`
+	To        string              `json:"to"`
+	Subject   string              `json:"subject"`
+	Preheader string              `json:"preheader"`
+	HTML      string              `json:"html"`
+	Text      string              `json:"text"`
+	Headers   map[string][]string `json:"headers"`
+	// Attachments and SendAt are carried over from the record.
+	Attachments []Attachment `json:"attachments,omitempty"`
+	SendAt      *time.Time   `json:"send_at,omitempty"`
+}
+
+// Addresses is the policy recipient addresses are checked against, the
+// addresses config of the service shared with the client.
+type Addresses struct {
+	AllowDisposable   bool     `koanf:"allow_disposable"`
+	AllowRoleAccounts bool     `koanf:"allow_role_accounts"`
+	DisposableDomains []string `koanf:"disposable_domains"`
+	RoleAccounts      []string `koanf:"role_accounts"`
+	CheckMX           bool     `koanf:"check_mx"`
+}
+
+// SMTPError is the failure of the SMTP server to take an email, e.g. to tell
+// them apart from the invalid records.
+type SMTPError struct {
+	Err error
+}
+
+func (e *SMTPError) Error() string {
+	return "could not send email: " + e.Err.Error()
+}
+
+func (e *SMTPError) Unwrap() error {
+	return e.Err
+}
+
+// Email states reported for the emails accepted over HTTP and gRPC.
+const (
+	EmailQueued   = "queued"
+	EmailProduced = "produced"
+	EmailSent     = "sent"
+	EmailFailed   = "failed"
+)
+
+// EmailStatus is where an accepted email stands.
+type EmailStatus struct {
+	MessageID string    `json:"message_id"`
+	State     string    `json:"state"`
+	Attempts  int       `json:"attempts"`
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Done reports whether the email won't change state anymore.
+func (s EmailStatus) Done() bool {
+	return s.State == EmailSent || s.State == EmailFailed
+}
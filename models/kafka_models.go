@@ -1,21 +1,114 @@
 package models
 
+import (
+	// Go Internal Packages
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	// Local Packages
+	errors "github.com/satya-ajayy/Emailer/errors"
+)
+
+// Record headers understood by the processor.
+const (
+	HeaderContentType    = "content-type"
+	HeaderSchemaVersion  = "schema-version"
+	HeaderIdempotencyKey = "idempotency-key"
+)
+
 type Record struct {
-	Key   []byte
+	Key       []byte
+	Value     []byte
+	Topic     string
+	Partition int32
+	Offset    int64
+	Timestamp time.Time
+	Headers   []RecordHeader
+}
+
+type RecordHeader struct {
+	Key   string
 	Value []byte
-	Topic string
+}
+
+// Position returns where the record sits in Kafka, e.g. "emails-to-send partition 3 offset 1042".
+func (r Record) Position() string {
+	return fmt.Sprintf("%s partition %d offset %d", r.Topic, r.Partition, r.Offset)
+}
+
+// UserLinks decodes the record value, ok is false when it isn't valid JSON.
+func (r Record) UserLinks() (userLinks UserLinks, ok bool) {
+	return userLinks, json.Unmarshal(r.Value, &userLinks) == nil
+}
+
+// Header returns the value of the first header with the given key.
+func (r Record) Header(key string) (string, bool) {
+	for _, h := range r.Headers {
+		if h.Key == key {
+			return string(h.Value), true
+		}
+	}
+	return "", false
+}
+
+// HeaderCarrier adapts the headers of a record to a propagation.TextMapCarrier,
+// to carry the trace context along with the record.
+type HeaderCarrier struct {
+	Record *Record
+}
+
+// Get returns the value of the header with the given key.
+func (c HeaderCarrier) Get(key string) string {
+	value, _ := c.Record.Header(key)
+	return value
+}
+
+// Set replaces the header with the given key.
+func (c HeaderCarrier) Set(key, value string) {
+	for i, h := range c.Record.Headers {
+		if h.Key == key {
+			c.Record.Headers[i].Value = []byte(value)
+			return
+		}
+	}
+	c.Record.Headers = append(c.Record.Headers, RecordHeader{Key: key, Value: []byte(value)})
+}
+
+// Keys returns the keys of all the headers.
+func (c HeaderCarrier) Keys() []string {
+	keys := make([]string, len(c.Record.Headers))
+	for i, h := range c.Record.Headers {
+		keys[i] = h.Key
+	}
+	return keys
+}
+
+// FailedRecord is a record that couldn't be processed, reported in alerts.
+type FailedRecord struct {
+	Record   Record
+	Err      error
+	Attempts int
 }
 
 type ConsumerConfig struct {
-	Brokers        []string
-	Name           string
-	Topic          string
-	RecordsPerPoll int
+	Name string
+	// Topics are consumed as regexes when Regex is set.
+	Topics           []string
+	Regex            bool
+	RecordsPerPoll   int
+	Rack             string
+	SessionTimeout   time.Duration
+	RebalanceTimeout time.Duration
 }
 
 type UserData struct {
 	UserName string `json:"user_name" schema:"user_name"`
 	MailID   string `json:"mail_id" schema:"mail_id"`
+	Locale   string `json:"locale" schema:"locale"`
+	Timezone string `json:"timezone" schema:"timezone"`
 }
 
 type Problem struct {
@@ -25,6 +118,73 @@ type Problem struct {
 }
 
 type UserLinks struct {
+	Template string    `json:"template" schema:"template"`
 	User     UserData  `json:"user" schema:"user"`
 	Problems []Problem `json:"problems" schema:"problems"`
+	// Data is the data of the templates other than the problems digest, e.g.
+	// {{.Data.reset_link}}.
+	Data        map[string]any `json:"data,omitempty" schema:"data"`
+	Attachments []Attachment   `json:"attachments,omitempty" schema:"attachments"`
+	// SendAt delays the email until then, up to the schedule max delay.
+	SendAt *time.Time `json:"send_at,omitempty" schema:"send_at"`
+}
+
+// Attachment is a file attached to the email, its content is base64 encoded
+// in JSON.
+type Attachment struct {
+	Filename    string `json:"filename" schema:"filename"`
+	ContentType string `json:"content_type,omitempty" schema:"content_type"`
+	Content     []byte `json:"content" schema:"content"`
+}
+
+// MaxAttachmentsSize bounds the total size of the attachments of an email.
+const MaxAttachmentsSize = 10 << 20
+
+// MaxEmailSize bounds the JSON of an email: its attachments base64 encoded
+// and a MiB for the rest.
+const MaxEmailSize = MaxAttachmentsSize/3*4 + 4 + 1<<20
+
+// Validate checks the attachments and schedule of the email, the rules shared
+// by the processor and the client. SendAt may be at most maxDelay after now.
+func (u UserLinks) Validate(now time.Time, maxDelay time.Duration) error {
+	ve := errors.ValidationErrs()
+
+	size := 0
+	for i, attachment := range u.Attachments {
+		field := fmt.Sprintf("attachments.%d", i)
+		if attachment.Filename == "" || strings.ContainsAny(attachment.Filename, "/\\\r\n") {
+			ve.Add(field+".filename", "must be a file name without path separators")
+		}
+		if attachment.ContentType != "" {
+			if _, _, err := mime.ParseMediaType(attachment.ContentType); err != nil {
+				ve.Add(field+".content_type", "must be a media type such as application/pdf")
+			}
+		}
+		if len(attachment.Content) == 0 {
+			ve.Add(field+".content", "cannot be empty")
+		}
+		size += len(attachment.Content)
+	}
+	if size > MaxAttachmentsSize {
+		ve.Add("attachments", fmt.Sprintf("cannot exceed %d MiB in total", MaxAttachmentsSize>>20))
+	}
+
+	if u.SendAt != nil && u.SendAt.Sub(now) > maxDelay {
+		ve.Add("send_at", fmt.Sprintf("cannot be more than %s ahead", maxDelay))
+	}
+
+	if ve.Len() > 0 {
+		return errors.ValidationFailedErr(ve.Err())
+	}
+	return nil
+}
+
+// Locale returns the locale of the recipient, used to pick the template messages.
+func (u UserLinks) Locale() string {
+	return u.User.Locale
+}
+
+// TimeZone returns the IANA time zone of the recipient, used to format dates.
+func (u UserLinks) TimeZone() string {
+	return u.User.Timezone
 }
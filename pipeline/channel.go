@@ -0,0 +1,107 @@
+package pipeline
+
+import (
+	// Go Internal Packages
+	"context"
+	"io"
+	"sync"
+
+	// Local Packages
+	errors "github.com/satya-ajayy/Emailer/errors"
+	models "github.com/satya-ajayy/Emailer/models"
+)
+
+// Channel is a Source receiving the records from a Go channel, e.g. to feed
+// the pipeline from memory in tests or from another component. Closing the
+// channel ends the source.
+type Channel struct {
+	records   <-chan models.Record
+	batchSize int
+	committed chan<- Batch
+}
+
+// NewChannel creates a new Channel returning batches of up to batchSize
+// records. When committed isn't nil every committed batch is sent to it, so
+// callers can wait for their records to be processed.
+func NewChannel(records <-chan models.Record, batchSize int, committed chan<- Batch) *Channel {
+	return &Channel{records: records, batchSize: batchSize, committed: committed}
+}
+
+// Next waits for a record and returns it with the ones already queued behind
+// it, returning io.EOF once the channel is closed and drained.
+func (c *Channel) Next(ctx context.Context) (Batch, error) {
+	var batch Batch
+	select {
+	case <-ctx.Done():
+		return batch, ctx.Err()
+	case record, ok := <-c.records:
+		if !ok {
+			return batch, io.EOF
+		}
+		batch.Records = append(batch.Records, record)
+	}
+
+	for len(batch.Records) < c.batchSize {
+		select {
+		case record, ok := <-c.records:
+			if !ok {
+				return batch, nil
+			}
+			batch.Records = append(batch.Records, record)
+		default:
+			return batch, nil
+		}
+	}
+	return batch, nil
+}
+
+// Commit hands the batch to the committed channel, if any.
+func (c *Channel) Commit(ctx context.Context, batch Batch) error {
+	if c.committed == nil {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case c.committed <- batch:
+		return nil
+	}
+}
+
+// Close does nothing, the channel is closed by its owner.
+func (c *Channel) Close() error {
+	return nil
+}
+
+// Queue is a bounded queue of records feeding a Channel source from other
+// goroutines, e.g. the emails received over HTTP to be sent by this instance.
+type Queue struct {
+	mu      sync.Mutex
+	records chan models.Record
+}
+
+// NewQueue creates a new Queue holding up to size records.
+func NewQueue(size int) *Queue {
+	return &Queue{records: make(chan models.Record, size)}
+}
+
+// Source returns the source pulling the queued records in batches of up to
+// batchSize records.
+func (q *Queue) Source(batchSize int) *Channel {
+	return NewChannel(q.records, batchSize, nil)
+}
+
+// Enqueue queues all the records, or none of them when the queue hasn't room
+// for them all.
+func (q *Queue) Enqueue(ctx context.Context, records []models.Record) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if cap(q.records)-len(q.records) < len(records) {
+		return errors.E(errors.Unavailable, "queue is full, try again later")
+	}
+	for _, record := range records {
+		q.records <- record
+	}
+	return nil
+}
@@ -0,0 +1,352 @@
+package pipeline
+
+import (
+	// Go Internal Packages
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	// Local Packages
+	apperrors "github.com/satya-ajayy/Emailer/errors"
+	metrics "github.com/satya-ajayy/Emailer/metrics"
+	models "github.com/satya-ajayy/Emailer/models"
+	tracing "github.com/satya-ajayy/Emailer/tracing"
+	alert "github.com/satya-ajayy/Emailer/utils/alert"
+
+	// External Packages
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+var tracer = tracing.Tracer("github.com/satya-ajayy/Emailer/pipeline")
+
+// Batch is a set of records pulled from a Source.
+type Batch struct {
+	Records []models.Record
+	// Poll is the span of the fetch when the source traces it, the process
+	// spans of the records link to it.
+	Poll trace.SpanContext
+	// Failed are the records the source couldn't read, e.g. a line over the
+	// size limit, reported as failed without being processed.
+	Failed []models.FailedRecord
+}
+
+// Source is where the pipeline pulls the records from.
+type Source interface {
+	// Next blocks until records are available and returns them. It returns
+	// io.EOF once a finite source is exhausted.
+	Next(ctx context.Context) (Batch, error)
+	// Commit acknowledges the batch returned by the last Next, once all its
+	// records were processed (or given up on).
+	Commit(ctx context.Context, batch Batch) error
+	// Close releases the source.
+	Close() error
+}
+
+// Holder is a Source consuming the uncommitted records again, e.g. after a
+// restart. The offsets of the held records aren't committed until they're
+// released, so the deferred records outlive the process that deferred them.
+type Holder interface {
+	// Hold keeps the record uncommitted until Release. The returned channel
+	// is closed once the record is handed over to another consumer, e.g. on
+	// a rebalance, which consumes it again.
+	Hold(record models.Record) (handedOver <-chan struct{})
+	// Release lets the record be committed with the next batch.
+	Release(record models.Record)
+}
+
+type MailProcessor interface {
+	ProcessRecord(ctx context.Context, record models.Record) error
+}
+
+// Tracker follows the outcome of the records, e.g. to report the status of
+// the emails.
+type Tracker interface {
+	Done(record models.Record, attempts int, err error)
+}
+
+// Prioritizer ranks the records, the records of a batch are processed by
+// descending priority.
+type Prioritizer interface {
+	Priority(record models.Record) int
+}
+
+// Deferred is returned by the processor for a record it can only process
+// from Until on, e.g. a scheduled email. The pipeline processes it again then,
+// without holding back the records behind it.
+type Deferred struct {
+	Until time.Time
+}
+
+func (d *Deferred) Error() string {
+	return fmt.Sprintf("deferred until %s", d.Until.Format(time.RFC3339))
+}
+
+// Options are the retry settings of the pipeline.
+type Options struct {
+	MaxAttempts  int
+	RetryBackoff time.Duration
+}
+
+// Stats counts the records the pipeline processed.
+type Stats struct {
+	Processed int
+	Failed    int
+}
+
+// Pipeline pulls the records from a source, hands them to the processor with
+// retries, alerts on the ones that fail and commits every batch.
+type Pipeline struct {
+	source    Source
+	processor MailProcessor
+	metrics   *metrics.Metrics
+	logger    *zap.Logger
+	alerts    alert.Notifier
+	options   atomic.Pointer[Options]
+	trackers  []Tracker
+	priority  Prioritizer
+	// deferred are the timers of the deferred records, waited for by Run
+	deferred sync.WaitGroup
+	statsMu  sync.Mutex
+	stats    Stats
+}
+
+// New creates a new Pipeline
+// (PS: Must call Run to start processing the records)
+func New(source Source, processor MailProcessor, opts Options, metrics *metrics.Metrics, logger *zap.Logger,
+	alerts alert.Notifier) *Pipeline {
+	p := &Pipeline{
+		source:    source,
+		processor: processor,
+		metrics:   metrics,
+		logger:    logger,
+		alerts:    alerts,
+	}
+	p.Apply(opts)
+	return p
+}
+
+// Apply switches the retry settings for the next records.
+func (p *Pipeline) Apply(opts Options) {
+	p.options.Store(&opts)
+}
+
+// Track reports the outcome of every record to the tracker, it must be called
+// before Run.
+func (p *Pipeline) Track(tracker Tracker) {
+	p.trackers = append(p.trackers, tracker)
+}
+
+// Prioritize processes the records of every batch by their priority, keeping
+// the order of the ones of equal priority. It must be called before Run.
+func (p *Pipeline) Prioritize(prioritizer Prioritizer) {
+	p.priority = prioritizer
+}
+
+// Stats returns the records processed so far.
+func (p *Pipeline) Stats() Stats {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+	return p.stats
+}
+
+// Run processes the records until the source is exhausted, returning nil, or
+// fails or the context is canceled. The source is closed on return, once the
+// deferred records were processed. The ones not due when the context is
+// canceled are left uncommitted when the source is a Holder, and reported as
+// failed otherwise.
+func (p *Pipeline) Run(ctx context.Context) error {
+	defer p.source.Close()
+	defer p.deferred.Wait()
+
+	for {
+		// Check if the context is canceled before polling
+		if ctx.Err() != nil {
+			p.logger.Warn("pipeline stopped: context canceled")
+			return ctx.Err()
+		}
+
+		batch, err := p.source.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		p.metrics.ObserveBatch(len(batch.Records))
+		if p.priority != nil {
+			// the sources commit whole batches, the order within one is free
+			slices.SortStableFunc(batch.Records, func(a, b models.Record) int {
+				return cmp.Compare(p.priority.Priority(b), p.priority.Priority(a))
+			})
+		}
+		success, failed, deferred := 0, 0, 0
+		for _, f := range batch.Failed {
+			p.done(ctx, f.Record, f.Attempts, f.Err)
+			failed++
+		}
+		for _, record := range batch.Records {
+			attempts, err := p.process(ctx, record, batch.Poll)
+			var later *Deferred
+			switch {
+			case errors.As(err, &later):
+				p.schedule(ctx, record, batch.Poll, later.Until)
+				deferred++
+			case p.done(ctx, record, attempts, err):
+				success++
+			default:
+				failed++
+			}
+		}
+
+		// Commit the processed records, the deferred ones wait in memory and a
+		// Holder keeps them uncommitted
+		if len(batch.Records)+len(batch.Failed) > 0 {
+			p.logger.Info("processed records", zap.Int("success", success), zap.Int("failed", failed),
+				zap.Int("deferred", deferred))
+		}
+		if err = p.source.Commit(ctx, batch); err != nil {
+			p.logger.Error("failed to commit processed records", zap.Error(err))
+		}
+	}
+}
+
+// done reports the outcome of the record to the trackers and the stats, and
+// alerts when it failed. It returns whether the record was processed.
+func (p *Pipeline) done(ctx context.Context, record models.Record, attempts int, err error) bool {
+	for _, tracker := range p.trackers {
+		tracker.Done(record, attempts, err)
+	}
+
+	p.statsMu.Lock()
+	if err != nil {
+		p.stats.Failed++
+	} else {
+		p.stats.Processed++
+	}
+	p.statsMu.Unlock()
+
+	if err == nil {
+		return true
+	}
+	p.logger.Error("failed to process record", zap.String("record", record.Position()), zap.Error(err))
+	failed := models.FailedRecord{Record: record, Err: err, Attempts: attempts}
+	if err = p.alerts.Notify(ctx, alert.FailedRecordEvent(failed)); err != nil {
+		p.logger.Error("failed to send alert", zap.Error(err))
+	}
+	return false
+}
+
+// schedule processes the record again once it's due, in its own goroutine so
+// the records behind it aren't held back. When the source is a Holder, the
+// record stays uncommitted until it's done, otherwise it's reported as failed
+// when the context is canceled first, as its batch was already committed.
+func (p *Pipeline) schedule(ctx context.Context, record models.Record, poll trace.SpanContext, until time.Time) {
+	p.logger.Info("deferring record", zap.String("record", record.Position()), zap.Time("until", until))
+	var handedOver <-chan struct{}
+	holder, holds := p.source.(Holder)
+	if holds {
+		handedOver = holder.Hold(record)
+	}
+
+	p.deferred.Add(1)
+	go func() {
+		defer p.deferred.Done()
+		for {
+			timer := time.NewTimer(time.Until(until))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				if holds {
+					p.logger.Warn("stopped before the scheduled time, record left uncommitted",
+						zap.String("record", record.Position()))
+					return
+				}
+				err := apperrors.E(apperrors.Unavailable, "stopped before the scheduled time", ctx.Err())
+				p.done(context.WithoutCancel(ctx), record, 0, err)
+				return
+			case <-handedOver:
+				timer.Stop()
+				p.logger.Info("deferred record handed over", zap.String("record", record.Position()))
+				return
+			case <-timer.C:
+			}
+
+			attempts, err := p.process(ctx, record, poll)
+			var later *Deferred
+			if errors.As(err, &later) {
+				until = later.Until
+				continue
+			}
+			p.done(ctx, record, attempts, err)
+			if holds {
+				holder.Release(record)
+			}
+			return
+		}
+	}()
+}
+
+// process hands the record to the processor, retrying transient failures up
+// to MaxAttempts times. Permanent failures (invalid records) are not retried.
+// It returns the number of attempts made.
+func (p *Pipeline) process(ctx context.Context, record models.Record, poll trace.SpanContext) (attempt int, err error) {
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.destination.name", record.Topic),
+			attribute.Int("messaging.destination.partition.id", int(record.Partition)),
+			attribute.Int64("messaging.kafka.offset", record.Offset),
+		),
+	}
+	if poll.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: poll}))
+	}
+	ctx, span := tracer.Start(tracing.Extract(ctx, record), "record.process", opts...)
+	defer func() {
+		// a deferred record isn't a failure
+		var later *Deferred
+		if errors.As(err, &later) {
+			span.End()
+			return
+		}
+		tracing.End(span, err)
+	}()
+
+	retry := p.options.Load()
+	for attempt = 1; attempt <= retry.MaxAttempts; attempt++ {
+		if err = p.processor.ProcessRecord(ctx, record); err == nil {
+			return attempt, nil
+		}
+		var later *Deferred
+		if errors.As(err, &later) {
+			span.SetAttributes(attribute.String("record.deferred_until", later.Until.Format(time.RFC3339)))
+			return attempt, err
+		}
+		if apperrors.IsPermanent(err) {
+			p.logger.Warn("dropping invalid record", zap.String("record", record.Position()), zap.Error(err))
+			p.metrics.Skipped("invalid")
+			return attempt, err
+		}
+		if attempt == retry.MaxAttempts {
+			return attempt, err
+		}
+
+		p.logger.Warn("failed to process record, retrying", zap.String("record", record.Position()),
+			zap.Int("attempt", attempt), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return attempt, ctx.Err()
+		case <-time.After(retry.RetryBackoff * time.Duration(attempt)):
+		}
+	}
+	return attempt, err
+}
@@ -0,0 +1,369 @@
+package pipeline
+
+import (
+	// Go Internal Packages
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	// Local Packages
+	apperrors "github.com/satya-ajayy/Emailer/errors"
+	metrics "github.com/satya-ajayy/Emailer/metrics"
+	models "github.com/satya-ajayy/Emailer/models"
+	alert "github.com/satya-ajayy/Emailer/utils/alert"
+
+	// External Packages
+	"go.uber.org/zap"
+)
+
+// scripted fails every record with its errors in turn, then processes it.
+type scripted struct {
+	mu       sync.Mutex
+	errs     map[int64][]error
+	calls    map[int64][]time.Time
+	order    []int64
+	prepared func(record models.Record)
+}
+
+func (s *scripted) ProcessRecord(_ context.Context, record models.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.calls == nil {
+		s.calls = make(map[int64][]time.Time)
+	}
+	s.calls[record.Offset] = append(s.calls[record.Offset], time.Now())
+	s.order = append(s.order, record.Offset)
+	if s.prepared != nil {
+		s.prepared(record)
+	}
+	if errs := s.errs[record.Offset]; len(errs) > 0 {
+		s.errs[record.Offset] = errs[1:]
+		return errs[0]
+	}
+	return nil
+}
+
+// outcomes tracks the records and counts the alerts.
+type outcomes struct {
+	mu       sync.Mutex
+	attempts map[int64]int
+	errs     map[int64]error
+	alerts   int
+}
+
+func (o *outcomes) Done(record models.Record, attempts int, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.attempts[record.Offset] = attempts
+	o.errs[record.Offset] = err
+}
+
+func (o *outcomes) Notify(context.Context, alert.Event) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.alerts++
+	return nil
+}
+
+// run processes the records through a Channel in batches of batchSize and
+// returns the committed batches.
+func run(t *testing.T, processor MailProcessor, opts Options, batchSize int, records []models.Record,
+	setup func(p *Pipeline)) (*Pipeline, *outcomes, []Batch) {
+	t.Helper()
+	queued := make(chan models.Record, len(records))
+	for _, record := range records {
+		queued <- record
+	}
+	close(queued)
+
+	committed := make(chan Batch, len(records))
+	tracked := &outcomes{attempts: make(map[int64]int), errs: make(map[int64]error)}
+	p := New(NewChannel(queued, batchSize, committed), processor, opts, metrics.New("test"), zap.NewNop(), tracked)
+	p.Track(tracked)
+	if setup != nil {
+		setup(p)
+	}
+	if err := p.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	close(committed)
+
+	var batches []Batch
+	for batch := range committed {
+		batches = append(batches, batch)
+	}
+	return p, tracked, batches
+}
+
+func records(offsets ...int64) []models.Record {
+	records := make([]models.Record, len(offsets))
+	for i, offset := range offsets {
+		records[i] = models.Record{Topic: "emails", Offset: offset}
+	}
+	return records
+}
+
+func TestRetries(t *testing.T) {
+	transient := fmt.Errorf("421 try again later")
+	invalid := apperrors.E(apperrors.Invalid, "error unmarshalling JSON")
+	tests := []struct {
+		name     string
+		errs     []error
+		attempts int
+		failed   bool
+	}{
+		{name: "success", attempts: 1},
+		{name: "transient then success", errs: []error{transient, transient}, attempts: 3},
+		{name: "retries exhausted", errs: []error{transient, transient, transient, transient}, attempts: 3, failed: true},
+		{name: "permanent not retried", errs: []error{invalid, transient}, attempts: 1, failed: true},
+		{name: "permanent after transient", errs: []error{transient, invalid}, attempts: 2, failed: true},
+	}
+
+	const backoff = 20 * time.Millisecond
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := &scripted{errs: map[int64][]error{1: tt.errs}}
+			p, tracked, _ := run(t, processor, Options{MaxAttempts: 3, RetryBackoff: backoff}, 1, records(1), nil)
+
+			calls := processor.calls[1]
+			if len(calls) != tt.attempts || tracked.attempts[1] != tt.attempts {
+				t.Fatalf("processed %d times, tracked %d attempts, want %d", len(calls), tracked.attempts[1], tt.attempts)
+			}
+			// the backoff grows with the attempts
+			for i := 1; i < len(calls); i++ {
+				if wait := calls[i].Sub(calls[i-1]); wait < backoff*time.Duration(i) {
+					t.Errorf("attempt %d after %s, want a backoff of %s", i+1, wait, backoff*time.Duration(i))
+				}
+			}
+
+			if failed := tracked.errs[1] != nil; failed != tt.failed {
+				t.Errorf("tracked error = %v, want failed %v", tracked.errs[1], tt.failed)
+			}
+			wantStats, wantAlerts := Stats{Processed: 1}, 0
+			if tt.failed {
+				wantStats, wantAlerts = Stats{Failed: 1}, 1
+			}
+			if stats := p.Stats(); stats != wantStats {
+				t.Errorf("Stats() = %+v, want %+v", stats, wantStats)
+			}
+			if tracked.alerts != wantAlerts {
+				t.Errorf("sent %d alerts, want %d", tracked.alerts, wantAlerts)
+			}
+		})
+	}
+}
+
+// committing calls commit with every batch it commits.
+type committing struct {
+	*Channel
+	commit func(batch Batch)
+}
+
+func (c committing) Commit(ctx context.Context, batch Batch) error {
+	c.commit(batch)
+	return c.Channel.Commit(ctx, batch)
+}
+
+func TestCommitPerBatch(t *testing.T) {
+	var mu sync.Mutex
+	var processed []int64
+	processor := &scripted{
+		errs: map[int64][]error{3: {apperrors.E(apperrors.Invalid, "bad record")}},
+		prepared: func(record models.Record) {
+			mu.Lock()
+			processed = append(processed, record.Offset)
+			mu.Unlock()
+		},
+	}
+
+	queued := make(chan models.Record, 5)
+	for _, record := range records(1, 2, 3, 4, 5) {
+		queued <- record
+	}
+	close(queued)
+
+	// every batch is committed once all its records were processed, failed
+	// ones included, before the next batch is pulled
+	var commits [][]int64
+	source := committing{Channel: NewChannel(queued, 2, nil), commit: func(batch Batch) {
+		var offsets []int64
+		for _, record := range batch.Records {
+			offsets = append(offsets, record.Offset)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if want := []int64{1, 2, 3, 4, 5}[:len(processed)]; !slices.Equal(processed, want) ||
+			processed[len(processed)-1] != offsets[len(offsets)-1] {
+			t.Errorf("processed %v when committing %v", processed, offsets)
+		}
+		commits = append(commits, offsets)
+	}}
+	p := New(source, processor, Options{MaxAttempts: 1}, metrics.New("test"), zap.NewNop(), alert.Discard)
+	if err := p.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(commits) != 3 || !slices.Equal(commits[0], []int64{1, 2}) || !slices.Equal(commits[1], []int64{3, 4}) ||
+		!slices.Equal(commits[2], []int64{5}) {
+		t.Errorf("committed %v, want [[1 2] [3 4] [5]]", commits)
+	}
+	if stats := p.Stats(); stats != (Stats{Processed: 4, Failed: 1}) {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+// byOffset ranks the records by the priorities of their offsets.
+type byOffset map[int64]int
+
+func (b byOffset) Priority(record models.Record) int {
+	return b[record.Offset]
+}
+
+func TestPriority(t *testing.T) {
+	processor := &scripted{}
+	priorities := byOffset{2: 10, 4: 10, 5: 5}
+	_, _, batches := run(t, processor, Options{MaxAttempts: 1}, 5, records(1, 2, 3, 4, 5), func(p *Pipeline) {
+		p.Prioritize(priorities)
+	})
+
+	// by descending priority, keeping the order of the equal ones
+	if want := []int64{2, 4, 5, 1, 3}; !slices.Equal(processor.order, want) {
+		t.Errorf("processed %v, want %v", processor.order, want)
+	}
+	if len(batches) != 1 || len(batches[0].Records) != 5 {
+		t.Errorf("committed %+v, want the whole batch", batches)
+	}
+}
+
+func TestDeferred(t *testing.T) {
+	until := time.Now().Add(50 * time.Millisecond)
+	processor := &scripted{errs: map[int64][]error{1: {&Deferred{Until: until}}}}
+	p, tracked, batches := run(t, processor, Options{MaxAttempts: 3}, 2, records(1, 2), nil)
+
+	// the deferred record doesn't hold back the next one, nor the commit
+	if want := []int64{1, 2, 1}; !slices.Equal(processor.order, want) {
+		t.Errorf("processed %v, want %v", processor.order, want)
+	}
+	if len(batches) != 1 {
+		t.Errorf("committed %d batches, want 1", len(batches))
+	}
+	if calls := processor.calls[1]; calls[1].Before(until) {
+		t.Errorf("deferred record processed at %s, before %s", calls[1], until)
+	}
+	if stats := p.Stats(); stats != (Stats{Processed: 2}) || tracked.errs[1] != nil {
+		t.Errorf("Stats() = %+v, record error = %v", stats, tracked.errs[1])
+	}
+}
+
+func TestDeferredCanceled(t *testing.T) {
+	processor := &scripted{errs: map[int64][]error{1: {&Deferred{Until: time.Now().Add(time.Hour)}}}}
+	queued := make(chan models.Record, 1)
+	queued <- records(1)[0]
+	committed := make(chan Batch, 1)
+	tracked := &outcomes{attempts: make(map[int64]int), errs: make(map[int64]error)}
+	p := New(NewChannel(queued, 1, committed), processor, Options{MaxAttempts: 1}, metrics.New("test"),
+		zap.NewNop(), tracked)
+	p.Track(tracked)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() { errs <- p.Run(ctx) }()
+	<-committed
+	cancel()
+
+	if err := <-errs; err == nil {
+		t.Fatal("Run() error = nil, want the context error")
+	}
+	if apperrors.KindOf(tracked.errs[1]) != apperrors.Unavailable || tracked.alerts != 1 {
+		t.Errorf("deferred record error = %v with %d alerts, want it failed", tracked.errs[1], tracked.alerts)
+	}
+}
+
+// holding is a Channel holding the deferred records like the Kafka consumer.
+type holding struct {
+	*Channel
+	mu         sync.Mutex
+	held       map[int64]bool
+	released   []int64
+	handedOver chan struct{}
+}
+
+func (h *holding) Hold(record models.Record) <-chan struct{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.held[record.Offset] = true
+	return h.handedOver
+}
+
+func (h *holding) Release(record models.Record) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.held, record.Offset)
+	h.released = append(h.released, record.Offset)
+}
+
+func TestDeferredHeld(t *testing.T) {
+	tests := []struct {
+		name      string
+		until     time.Duration
+		stop      func(cancel context.CancelFunc, h *holding)
+		processed bool
+		released  bool
+	}{
+		{name: "sent once due", until: 20 * time.Millisecond, processed: true, released: true},
+		{name: "stopped before due", until: time.Hour, stop: func(cancel context.CancelFunc, _ *holding) { cancel() }},
+		{name: "handed over", until: time.Hour, stop: func(cancel context.CancelFunc, h *holding) {
+			close(h.handedOver)
+			cancel()
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := &scripted{errs: map[int64][]error{1: {&Deferred{Until: time.Now().Add(tt.until)}}}}
+			queued := make(chan models.Record, 1)
+			queued <- records(1)[0]
+			committed := make(chan Batch, 1)
+			source := &holding{Channel: NewChannel(queued, 1, committed), held: make(map[int64]bool),
+				handedOver: make(chan struct{})}
+			tracked := &outcomes{attempts: make(map[int64]int), errs: make(map[int64]error)}
+			p := New(source, processor, Options{MaxAttempts: 1}, metrics.New("test"), zap.NewNop(), tracked)
+			p.Track(tracked)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			errs := make(chan error, 1)
+			go func() { errs <- p.Run(ctx) }()
+
+			// the record is held before its batch is committed
+			<-committed
+			source.mu.Lock()
+			if !source.held[1] && len(source.released) == 0 {
+				t.Error("deferred record not held when its batch was committed")
+			}
+			source.mu.Unlock()
+
+			if tt.stop != nil {
+				tt.stop(cancel, source)
+			} else {
+				close(queued)
+			}
+			<-errs
+
+			// a held record is never reported failed, it's consumed again
+			if processed := len(processor.calls[1]) == 2; processed != tt.processed {
+				t.Errorf("processed again = %v, want %v", processed, tt.processed)
+			}
+			if released := slices.Equal(source.released, []int64{1}); released != tt.released {
+				t.Errorf("released %v, want released %v", source.released, tt.released)
+			}
+			if tracked.alerts != 0 || tracked.errs[1] != nil {
+				t.Errorf("record error = %v with %d alerts, want none", tracked.errs[1], tracked.alerts)
+			}
+		})
+	}
+}
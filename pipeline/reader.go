@@ -0,0 +1,133 @@
+package pipeline
+
+import (
+	// Go Internal Packages
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	// Local Packages
+	errors "github.com/satya-ajayy/Emailer/errors"
+	models "github.com/satya-ajayy/Emailer/models"
+)
+
+// maxLineSize bounds a JSONL line, i.e. a single record value.
+const maxLineSize = 10 * 1024 * 1024
+
+// Reader is a Source reading JSONL, one record value per line, e.g. records
+// exported from Kafka or requests.jsonl. The records are numbered by line in
+// the Offset, with the name as their Topic, and blank or whitespace-only lines
+// are skipped. The lines over maxLineSize are reported as failed records.
+type Reader struct {
+	name      string
+	closer    io.Closer
+	reader    *bufio.Reader
+	batchSize int
+	line      int64
+}
+
+// NewReader creates a new Reader returning batches of up to batchSize
+// records. It doesn't close r.
+func NewReader(name string, r io.Reader, batchSize int) *Reader {
+	return &Reader{name: name, reader: bufio.NewReaderSize(r, 64*1024), batchSize: batchSize}
+}
+
+// NewFile creates a new Reader over the JSONL file at path, closed with the
+// source.
+func NewFile(path string, batchSize int) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %v", path, err)
+	}
+	r := NewReader(path, f, batchSize)
+	r.closer = f
+	return r, nil
+}
+
+// NewStdin creates a new Reader over the standard input.
+func NewStdin(batchSize int) *Reader {
+	return NewReader("stdin", os.Stdin, batchSize)
+}
+
+// Next reads the next lines, returning io.EOF once they're all read.
+func (r *Reader) Next(ctx context.Context) (Batch, error) {
+	var batch Batch
+	for len(batch.Records)+len(batch.Failed) < r.batchSize {
+		if err := ctx.Err(); err != nil {
+			return batch, err
+		}
+		line, tooLong, err := r.readLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return batch, fmt.Errorf("error reading %s: %v", r.name, err)
+		}
+		r.line++
+
+		record := models.Record{Topic: r.name, Offset: r.line, Timestamp: time.Now()}
+		if tooLong {
+			batch.Failed = append(batch.Failed, models.FailedRecord{
+				Record: record,
+				Err:    errors.E(errors.Invalid, fmt.Sprintf("line is longer than %d bytes", maxLineSize)),
+			})
+			continue
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		record.Value = line
+		batch.Records = append(batch.Records, record)
+	}
+
+	if len(batch.Records) == 0 && len(batch.Failed) == 0 {
+		return batch, io.EOF
+	}
+	return batch, nil
+}
+
+// readLine reads the next line without its line ending, returning io.EOF
+// once the input is exhausted. The lines over maxLineSize are read through
+// without being kept and reported as tooLong.
+func (r *Reader) readLine() (line []byte, tooLong bool, err error) {
+	read := 0
+	for {
+		chunk, err := r.reader.ReadSlice('\n')
+		read += len(chunk)
+		if read <= maxLineSize+len("\r\n") {
+			line = append(line, chunk...)
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == io.EOF && read > 0 {
+			err = nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+
+		line = bytes.TrimSuffix(bytes.TrimSuffix(line, []byte("\n")), []byte("\r"))
+		if read > maxLineSize+len("\r\n") || len(line) > maxLineSize {
+			return nil, true, nil
+		}
+		return line, false, nil
+	}
+}
+
+// Commit does nothing, the lines are read once.
+func (r *Reader) Commit(context.Context, Batch) error {
+	return nil
+}
+
+// Close closes the file the reader was created with.
+func (r *Reader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
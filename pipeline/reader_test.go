@@ -0,0 +1,109 @@
+package pipeline
+
+import (
+	// Go Internal Packages
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	// Local Packages
+	apperrors "github.com/satya-ajayy/Emailer/errors"
+)
+
+func TestReader(t *testing.T) {
+	long := `{"pad":"` + strings.Repeat("x", maxLineSize) + `"}`
+	input := strings.Join([]string{
+		`{"n":1}`,
+		``,
+		"  \t ",
+		`{"n":4}` + "\r",
+		long,
+		`{"n":6}`,
+		" ",
+		`{"n":8}`,
+	}, "\n")
+
+	r := NewReader("emails.jsonl", strings.NewReader(input), 2)
+	var values []string
+	var offsets, failed []int64
+	for {
+		batch, err := r.Next(context.Background())
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		if len(batch.Records)+len(batch.Failed) > 2 {
+			t.Errorf("batch of %d records and %d failed, want at most 2", len(batch.Records), len(batch.Failed))
+		}
+		for _, record := range batch.Records {
+			if record.Topic != "emails.jsonl" {
+				t.Errorf("record topic = %q", record.Topic)
+			}
+			values = append(values, string(record.Value))
+			offsets = append(offsets, record.Offset)
+		}
+		for _, f := range batch.Failed {
+			if !apperrors.IsPermanent(f.Err) || f.Record.Value != nil {
+				t.Errorf("failed line %d = %v with %d bytes", f.Record.Offset, f.Err, len(f.Record.Value))
+			}
+			failed = append(failed, f.Record.Offset)
+		}
+	}
+
+	want := []string{`{"n":1}`, `{"n":4}`, `{"n":6}`, `{"n":8}`}
+	if strings.Join(values, ",") != strings.Join(want, ",") {
+		t.Errorf("read %v, want %v", values, want)
+	}
+	wantOffsets := []int64{1, 4, 6, 8}
+	for i := range wantOffsets {
+		if i >= len(offsets) || offsets[i] != wantOffsets[i] {
+			t.Fatalf("offsets = %v, want %v", offsets, wantOffsets)
+		}
+	}
+	if len(failed) != 1 || failed[0] != 5 {
+		t.Errorf("failed lines = %v, want [5]", failed)
+	}
+}
+
+func TestReaderLineLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		ending string
+		failed bool
+	}{
+		{name: "at the limit", size: maxLineSize, ending: "\n"},
+		{name: "at the limit with CRLF", size: maxLineSize, ending: "\r\n"},
+		{name: "at the limit without newline", size: maxLineSize},
+		{name: "over the limit", size: maxLineSize + 1, ending: "\n", failed: true},
+		{name: "over the limit without newline", size: maxLineSize + 1, failed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := strings.Repeat("x", tt.size) + tt.ending + "{}\n"
+			batch, err := NewReader("big.jsonl", strings.NewReader(input), 10).Next(context.Background())
+			if tt.ending == "" {
+				// the line isn't followed by another one
+				batch, err = NewReader("big.jsonl", strings.NewReader(strings.Repeat("x", tt.size)), 10).
+					Next(context.Background())
+			}
+			if err != nil {
+				t.Fatalf("Next() error = %v", err)
+			}
+			if got := len(batch.Failed) == 1; got != tt.failed {
+				t.Fatalf("failed = %v, want %v", batch.Failed, tt.failed)
+			}
+			if !tt.failed && len(batch.Records[0].Value) != tt.size {
+				t.Errorf("read %d bytes, want %d", len(batch.Records[0].Value), tt.size)
+			}
+			if tt.ending != "" && string(batch.Records[len(batch.Records)-1].Value) != "{}" {
+				t.Errorf("the line after wasn't read")
+			}
+		})
+	}
+}
@@ -0,0 +1,5 @@
+// Package emailerv1 holds the gRPC API of the emailer, generated from
+// emailer.proto.
+package emailerv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative emailer/v1/emailer.proto
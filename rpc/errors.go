@@ -0,0 +1,78 @@
+package rpc
+
+import (
+	// Go Internal Packages
+	"context"
+
+	// Local Packages
+	errors "github.com/satya-ajayy/Emailer/errors"
+
+	// External Packages
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// kindCodes maps the application error kinds to gRPC status codes.
+var kindCodes = map[errors.Kind]codes.Code{
+	errors.Other:        codes.Unknown,
+	errors.Internal:     codes.Internal,
+	errors.Conflict:     codes.AlreadyExists,
+	errors.Invalid:      codes.InvalidArgument,
+	errors.NotFound:     codes.NotFound,
+	errors.Unauthorized: codes.Unauthenticated,
+	errors.Forbidden:    codes.PermissionDenied,
+	errors.Unavailable:  codes.Unavailable,
+}
+
+// Status converts the error into a gRPC status error, the way
+// response.RespondError maps it to an HTTP status. Validation errors are
+// attached as BadRequest field violations.
+func Status(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	var appErr *errors.Error
+	if !errors.As(err, &appErr) {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	code, ok := kindCodes[appErr.Kind]
+	if !ok {
+		code = codes.Unknown
+	}
+	st := status.New(code, message(appErr))
+
+	var ve errors.ValidationErrors
+	if !errors.As(err, &ve) {
+		return st.Err()
+	}
+	violations := make([]*errdetails.BadRequest_FieldViolation, len(ve))
+	for i, fe := range ve {
+		violations[i] = &errdetails.BadRequest_FieldViolation{Field: fe.Field, Description: fe.Error}
+	}
+	detailed, detailsErr := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if detailsErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// message returns the message of the error, with the wrapped error for invalid
+// input as response.RespondError does.
+func message(err *errors.Error) string {
+	if err.Kind == errors.Invalid && err.WrappedErr != nil {
+		var ve errors.ValidationErrors
+		if !errors.As(err.WrappedErr, &ve) {
+			return err.WrappedErr.Error()
+		}
+	}
+	return err.Message
+}
@@ -0,0 +1,107 @@
+package rpc
+
+import (
+	// Go Internal Packages
+	"context"
+	"fmt"
+	"testing"
+
+	// Local Packages
+	errors "github.com/satya-ajayy/Emailer/errors"
+
+	// External Packages
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatusCodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		code    codes.Code
+		message string
+	}{
+		{name: "other", err: errors.E(errors.Other, "boom"), code: codes.Unknown, message: "boom"},
+		{name: "internal", err: errors.E(errors.Internal, "db down"), code: codes.Internal, message: "db down"},
+		{name: "conflict", err: errors.E(errors.Conflict, "exists"), code: codes.AlreadyExists, message: "exists"},
+		{name: "invalid", err: errors.E(errors.Invalid, "bad"), code: codes.InvalidArgument, message: "bad"},
+		{
+			name:    "invalid with cause",
+			err:     errors.InvalidBodyErr(fmt.Errorf("unexpected EOF")),
+			code:    codes.InvalidArgument,
+			message: "unexpected EOF",
+		},
+		{name: "not found", err: errors.E(errors.NotFound, "no email"), code: codes.NotFound, message: "no email"},
+		{name: "unauthorized", err: errors.E(errors.Unauthorized, "no token"), code: codes.Unauthenticated,
+			message: "no token"},
+		{name: "forbidden", err: errors.E(errors.Forbidden, "denied"), code: codes.PermissionDenied, message: "denied"},
+		{name: "unavailable", err: errors.E(errors.Unavailable, "queue is full"), code: codes.Unavailable,
+			message: "queue is full"},
+		{name: "unknown kind", err: errors.E(errors.Kind(99), "odd"), code: codes.Unknown, message: "odd"},
+		{name: "wrapped", err: fmt.Errorf("sending: %w", errors.E(errors.NotFound, "no email")), code: codes.NotFound,
+			message: "no email"},
+		{name: "canceled", err: context.Canceled, code: codes.Canceled, message: "context canceled"},
+		{name: "deadline", err: fmt.Errorf("enqueue: %w", context.DeadlineExceeded), code: codes.DeadlineExceeded,
+			message: "enqueue: context deadline exceeded"},
+		{name: "plain error", err: fmt.Errorf("kafka down"), code: codes.Internal, message: "kafka down"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := status.Convert(Status(tt.err))
+			if st.Code() != tt.code || st.Message() != tt.message {
+				t.Errorf("Status() = %s %q, want %s %q", st.Code(), st.Message(), tt.code, tt.message)
+			}
+			if len(st.Details()) != 0 {
+				t.Errorf("Status() details = %v, want none", st.Details())
+			}
+		})
+	}
+
+	if Status(nil) != nil {
+		t.Error("Status(nil) != nil")
+	}
+}
+
+func TestStatusViolations(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		violations [][2]string
+	}{
+		{name: "empty param", err: errors.EmptyParamErr("message_ids"),
+			violations: [][2]string{{"message_ids", "cannot be empty"}}},
+		{name: "batch", err: func() error {
+			ve := errors.ValidationErrs()
+			ve.Add("emails.0.user.mail_id", "must be an email address")
+			ve.Add("emails.2.attachments.0.filename", "must be a file name without path separators")
+			return errors.ValidationFailedErr(ve.Err())
+		}(), violations: [][2]string{
+			{"emails.0.user.mail_id", "must be an email address"},
+			{"emails.2.attachments.0.filename", "must be a file name without path separators"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := status.Convert(Status(tt.err))
+			if st.Code() != codes.InvalidArgument || st.Message() != "validation failed" {
+				t.Errorf("Status() = %s %q, want InvalidArgument \"validation failed\"", st.Code(), st.Message())
+			}
+			if len(st.Details()) != 1 {
+				t.Fatalf("Status() details = %v, want one BadRequest", st.Details())
+			}
+			badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+			if !ok || len(badRequest.GetFieldViolations()) != len(tt.violations) {
+				t.Fatalf("Status() details = %v, want %d violations", st.Details(), len(tt.violations))
+			}
+			for i, v := range badRequest.GetFieldViolations() {
+				if v.GetField() != tt.violations[i][0] || v.GetDescription() != tt.violations[i][1] {
+					t.Errorf("violation %d = %s: %s, want %s: %s", i, v.GetField(), v.GetDescription(),
+						tt.violations[i][0], tt.violations[i][1])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package rpc
+
+import (
+	// Go Internal Packages
+	"context"
+	"encoding/json"
+	"time"
+
+	// Local Packages
+	config "github.com/satya-ajayy/Emailer/config"
+	errors "github.com/satya-ajayy/Emailer/errors"
+	smiddleware "github.com/satya-ajayy/Emailer/http/middlewares"
+	models "github.com/satya-ajayy/Emailer/models"
+	emailerv1 "github.com/satya-ajayy/Emailer/proto/emailer/v1"
+	ingest "github.com/satya-ajayy/Emailer/services/ingest"
+
+	// External Packages
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// EmailServer serves the EmailService of the gRPC API over the ingest service
+// shared with the HTTP API.
+type EmailServer struct {
+	emailerv1.UnimplementedEmailServiceServer
+
+	logger *zap.Logger
+	ingest *ingest.IngestService
+}
+
+// NewServer creates the gRPC server with the EmailService registered, the
+// calls need the token in their authorization metadata unless it's empty. It
+// takes the batches as large as the HTTP API does, instead of gRPC's 4 MiB.
+// (PS: Must call Serve to start serving the requests)
+func NewServer(logger *zap.Logger, ingest *ingest.IngestService, token config.Secret) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.MaxRecvMsgSize(max(ingest.MaxBatch(), 1)*models.MaxEmailSize),
+		grpc.ChainUnaryInterceptor(unaryLogger(logger), unaryAuth(token)),
+		grpc.ChainStreamInterceptor(streamLogger(logger), streamAuth(token)),
+	)
+	emailerv1.RegisterEmailServiceServer(server, &EmailServer{logger: logger, ingest: ingest})
+	return server
+}
+
+// SendEmail validates and renders the email and accepts it to be sent
+func (s *EmailServer) SendEmail(ctx context.Context, req *emailerv1.SendEmailRequest) (*emailerv1.SendEmailResponse, error) {
+	if req.GetEmail() == nil {
+		return nil, Status(errors.EmptyParamErr("email"))
+	}
+
+	id, err := s.ingest.Send(ctx, toEmail(req.GetEmail()))
+	if err != nil {
+		return nil, Status(err)
+	}
+	return &emailerv1.SendEmailResponse{MessageId: id}, nil
+}
+
+// SendBatch accepts all the emails of the batch, or none of them when any is
+// invalid
+func (s *EmailServer) SendBatch(ctx context.Context, req *emailerv1.SendBatchRequest) (*emailerv1.SendBatchResponse, error) {
+	emails := make([]ingest.Email, len(req.GetEmails()))
+	for i, email := range req.GetEmails() {
+		emails[i] = toEmail(email)
+	}
+
+	ids, err := s.ingest.SendBatch(ctx, emails)
+	if err != nil {
+		return nil, Status(err)
+	}
+	return &emailerv1.SendBatchResponse{MessageIds: ids}, nil
+}
+
+// GetEmailStatus returns the status of an accepted email
+func (s *EmailServer) GetEmailStatus(_ context.Context, req *emailerv1.GetEmailStatusRequest) (*emailerv1.EmailStatus, error) {
+	emailStatus, err := s.ingest.Status(req.GetMessageId())
+	if err != nil {
+		return nil, Status(err)
+	}
+	return toStatus(emailStatus), nil
+}
+
+// WatchStatus streams the status of the emails and then every change, until
+// they're all sent or failed, or the watch timeout in the produce mode.
+func (s *EmailServer) WatchStatus(req *emailerv1.WatchStatusRequest, stream grpc.ServerStreamingServer[emailerv1.EmailStatus]) error {
+	err := s.ingest.Watch(stream.Context(), req.GetMessageIds(), func(emailStatus models.EmailStatus) error {
+		return stream.Send(toStatus(emailStatus))
+	})
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return Status(err)
+}
+
+// toEmail converts the email to the JSON of the Kafka record values.
+func toEmail(email *emailerv1.Email) ingest.Email {
+	userLinks := models.UserLinks{
+		Template: email.GetTemplate(),
+		User: models.UserData{
+			UserName: email.GetUser().GetUserName(),
+			MailID:   email.GetUser().GetMailId(),
+			Locale:   email.GetUser().GetLocale(),
+			Timezone: email.GetUser().GetTimezone(),
+		},
+	}
+	for _, problem := range email.GetProblems() {
+		userLinks.Problems = append(userLinks.Problems, models.Problem{
+			ID:   problem.GetId(),
+			Name: problem.GetName(),
+			Link: problem.GetLink(),
+		})
+	}
+	if email.GetData() != nil {
+		userLinks.Data = email.GetData().AsMap()
+	}
+	for _, attachment := range email.GetAttachments() {
+		userLinks.Attachments = append(userLinks.Attachments, models.Attachment{
+			Filename:    attachment.GetFilename(),
+			ContentType: attachment.GetContentType(),
+			Content:     attachment.GetContent(),
+		})
+	}
+	if email.GetSendAt() != nil {
+		sendAt := email.GetSendAt().AsTime()
+		userLinks.SendAt = &sendAt
+	}
+
+	// cannot fail, AsMap turns the NaN and infinite numbers into strings
+	payload, _ := json.Marshal(userLinks)
+	return ingest.Email{Payload: payload, IdempotencyKey: email.GetIdempotencyKey()}
+}
+
+// states maps the email states to the API ones.
+var states = map[string]emailerv1.State{
+	models.EmailQueued:   emailerv1.State_STATE_QUEUED,
+	models.EmailProduced: emailerv1.State_STATE_PRODUCED,
+	models.EmailSent:     emailerv1.State_STATE_SENT,
+	models.EmailFailed:   emailerv1.State_STATE_FAILED,
+}
+
+func toStatus(emailStatus models.EmailStatus) *emailerv1.EmailStatus {
+	return &emailerv1.EmailStatus{
+		MessageId: emailStatus.MessageID,
+		State:     states[emailStatus.State],
+		Attempts:  int32(emailStatus.Attempts),
+		Error:     emailStatus.Error,
+		UpdatedAt: timestamppb.New(emailStatus.UpdatedAt),
+	}
+}
+
+// unaryLogger logs the calls the way the HTTP middleware logs the requests.
+func unaryLogger(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logger.Info("Served", zap.String("method", info.FullMethod), zap.String("code", status.Code(err).String()),
+			zap.Duration("duration", time.Since(start)))
+		return resp, err
+	}
+}
+
+// streamLogger logs the streams once they end.
+func streamLogger(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logger.Info("Served", zap.String("method", info.FullMethod), zap.String("code", status.Code(err).String()),
+			zap.Duration("duration", time.Since(start)))
+		return err
+	}
+}
+
+// authorize checks the bearer token of the call, an empty token lets every
+// call through.
+func authorize(ctx context.Context, token config.Secret) error {
+	if token == "" {
+		return nil
+	}
+	if values := metadata.ValueFromIncomingContext(ctx, "authorization"); len(values) == 1 &&
+		smiddleware.ValidToken(values[0], token) {
+		return nil
+	}
+	return Status(errors.E(errors.Unauthorized, "missing or invalid bearer token"))
+}
+
+// unaryAuth rejects the calls without the token.
+func unaryAuth(token config.Secret) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := authorize(ctx, token); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// streamAuth rejects the streams without the token.
+func streamAuth(token config.Secret) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorize(ss.Context(), token); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,163 @@
+package rpc
+
+import (
+	// Go Internal Packages
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	// Local Packages
+	config "github.com/satya-ajayy/Emailer/config"
+	metrics "github.com/satya-ajayy/Emailer/metrics"
+	models "github.com/satya-ajayy/Emailer/models"
+	emailerv1 "github.com/satya-ajayy/Emailer/proto/emailer/v1"
+	ingest "github.com/satya-ajayy/Emailer/services/ingest"
+	processors "github.com/satya-ajayy/Emailer/services/processors"
+	templates "github.com/satya-ajayy/Emailer/templates"
+	alert "github.com/satya-ajayy/Emailer/utils/alert"
+	mailaddr "github.com/satya-ajayy/Emailer/utils/mailaddr"
+
+	// External Packages
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestToEmail(t *testing.T) {
+	sendAt := time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)
+	data, err := structpb.NewStruct(map[string]any{"reset_link": "https://example.com/reset", "expires_in": 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	email := toEmail(&emailerv1.Email{
+		IdempotencyKey: "weekly-2026-42",
+		Template:       "password-reset",
+		User:           &emailerv1.User{UserName: "Ada", MailId: "ada@example.com", Locale: "fr", Timezone: "Europe/Paris"},
+		Problems:       []*emailerv1.Problem{{Id: "1", Name: "Two Sum", Link: "https://example.com/problems/1"}},
+		Data:           data,
+		Attachments:    []*emailerv1.Attachment{{Filename: "report.pdf", ContentType: "application/pdf", Content: []byte("%PDF")}},
+		SendAt:         timestamppb.New(sendAt),
+	})
+	if email.IdempotencyKey != "weekly-2026-42" {
+		t.Errorf("idempotency key = %q", email.IdempotencyKey)
+	}
+
+	// the payload is shaped as the Kafka record values
+	var got models.UserLinks
+	if err = json.Unmarshal(email.Payload, &got); err != nil {
+		t.Fatalf("payload %s: %v", email.Payload, err)
+	}
+	want := models.UserLinks{
+		Template:    "password-reset",
+		User:        models.UserData{UserName: "Ada", MailID: "ada@example.com", Locale: "fr", Timezone: "Europe/Paris"},
+		Problems:    []models.Problem{{ID: "1", Name: "Two Sum", Link: "https://example.com/problems/1"}},
+		Data:        map[string]any{"reset_link": "https://example.com/reset", "expires_in": float64(30)},
+		Attachments: []models.Attachment{{Filename: "report.pdf", ContentType: "application/pdf", Content: []byte("%PDF")}},
+		SendAt:      &sendAt,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("payload = %+v, want %+v", got, want)
+	}
+}
+
+func TestToEmailEmpty(t *testing.T) {
+	email := toEmail(&emailerv1.Email{User: &emailerv1.User{MailId: "ada@example.com"}})
+	// the optional fields are left out, as in the records
+	if want := `{"template":"","user":{"user_name":"","mail_id":"ada@example.com","locale":"","timezone":""},"problems":null}`; string(email.Payload) != want {
+		t.Errorf("payload = %s, want %s", email.Payload, want)
+	}
+}
+
+func TestToEmailNaN(t *testing.T) {
+	data := &structpb.Struct{Fields: map[string]*structpb.Value{"ratio": structpb.NewNumberValue(math.NaN())}}
+	email := toEmail(&emailerv1.Email{Data: data})
+
+	var got models.UserLinks
+	if err := json.Unmarshal(email.Payload, &got); err != nil || got.Data["ratio"] != "NaN" {
+		t.Errorf("payload = %s, want the NaN as a string", email.Payload)
+	}
+}
+
+// queued keeps the enqueued records.
+type queued struct {
+	records []models.Record
+}
+
+func (q *queued) Enqueue(_ context.Context, records []models.Record) error {
+	q.records = append(q.records, records...)
+	return nil
+}
+
+// serve starts the EmailService over the ingest service and returns a client.
+func serve(t *testing.T, maxBatch int) (emailerv1.EmailServiceClient, *queued) {
+	t.Helper()
+	logger := zap.NewNop()
+	registry, err := templates.NewRegistry(logger, config.Templates{Default: "problems", DefaultLocale: "en"},
+		alert.Discard)
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+	processor := processors.NewProcessor(logger, config.Credentials{MailID: "emailer@example.com"}, registry,
+		mailaddr.NewValidator(config.Addresses{}, nil), metrics.New("test"),
+		config.Idempotency{MaxKeys: 10, TTL: time.Hour}, config.Schedule{MaxDelay: time.Minute}, nil, false)
+	queue := &queued{}
+	emails := ingest.NewService(logger, processor, queue, config.Ingest{Mode: config.IngestDirect,
+		MaxBatch: maxBatch, StatusTTL: time.Hour, MaxStatuses: 10})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer(logger, emails, "")
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return emailerv1.NewEmailServiceClient(conn), queue
+}
+
+// largeEmail is an email with an attachment of size bytes.
+func largeEmail(size int) *emailerv1.Email {
+	return &emailerv1.Email{
+		User:        &emailerv1.User{UserName: "Ada", MailId: "ada@example.com"},
+		Problems:    []*emailerv1.Problem{{Id: "1", Name: "Two Sum", Link: "https://example.com/problems/1"}},
+		Attachments: []*emailerv1.Attachment{{Filename: "report.pdf", Content: bytes.Repeat([]byte("x"), size)}},
+	}
+}
+
+func TestLargeMessages(t *testing.T) {
+	const size = 6 << 20 // over gRPC's default 4 MiB
+	client, queue := serve(t, 2)
+	ctx := context.Background()
+
+	if _, err := client.SendEmail(ctx, &emailerv1.SendEmailRequest{Email: largeEmail(size)}); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	batch := &emailerv1.SendBatchRequest{Emails: []*emailerv1.Email{largeEmail(size), largeEmail(size)}}
+	if _, err := client.SendBatch(ctx, batch); err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+	if len(queue.records) != 3 {
+		t.Errorf("queued %d emails, want 3", len(queue.records))
+	}
+
+	// the batches are bounded as over HTTP
+	batch.Emails = append(batch.Emails, largeEmail(models.MaxAttachmentsSize), largeEmail(models.MaxAttachmentsSize))
+	if _, err := client.SendBatch(ctx, batch); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("SendBatch() error = %v, want ResourceExhausted", err)
+	}
+}
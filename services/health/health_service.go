@@ -5,7 +5,7 @@ import (
 	"context"
 
 	// Local Packages
-	kafka "emailer/kafka"
+	kafka "github.com/satya-ajayy/Emailer/kafka"
 
 	// External Packages
 	"go.uber.org/zap"
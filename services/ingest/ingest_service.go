@@ -0,0 +1,295 @@
+package ingest
+
+import (
+	// Go Internal Packages
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	// Local Packages
+	config "github.com/satya-ajayy/Emailer/config"
+	errors "github.com/satya-ajayy/Emailer/errors"
+	models "github.com/satya-ajayy/Emailer/models"
+	processors "github.com/satya-ajayy/Emailer/services/processors"
+
+	// External Packages
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Topic is the topic set on the records received over HTTP and gRPC and sent
+// by this instance, so they can be told apart in the logs and alerts.
+const Topic = "api"
+
+// Queue takes the accepted emails, producing them to Kafka or queueing them to
+// be sent by this instance depending on the ingest mode.
+type Queue interface {
+	Enqueue(ctx context.Context, records []models.Record) error
+}
+
+// Email is an email received over HTTP or gRPC.
+type Email struct {
+	// Payload is the email as JSON, shaped as the Kafka record values.
+	Payload []byte
+	// IdempotencyKey is used as the message ID when set.
+	IdempotencyKey string
+}
+
+// IngestService validates the emails received over HTTP and gRPC, hands them
+// to the queue and keeps their status.
+type IngestService struct {
+	logger       *zap.Logger
+	processor    *processors.MailProcessor
+	queue        Queue
+	state        string
+	maxBatch     int
+	statuses     *statuses
+	watchTimeout time.Duration // bounds the watches in the produce mode, 0 in the direct one
+}
+
+// NewService creates a new IngestService instance and returns the instance.
+func NewService(logger *zap.Logger, processor *processors.MailProcessor, queue Queue, conf config.Ingest) *IngestService {
+	state, watchTimeout := models.EmailProduced, conf.WatchTimeout
+	if conf.Mode == config.IngestDirect {
+		state, watchTimeout = models.EmailQueued, 0
+	}
+	return &IngestService{
+		logger:       logger,
+		processor:    processor,
+		queue:        queue,
+		state:        state,
+		maxBatch:     conf.MaxBatch,
+		statuses:     newStatuses(conf.MaxStatuses, conf.StatusTTL),
+		watchTimeout: watchTimeout,
+	}
+}
+
+// MaxBatch returns the most emails a batch can have.
+func (s *IngestService) MaxBatch() int {
+	return s.maxBatch
+}
+
+// Send validates and renders the email and accepts it to be sent, returning
+// its message ID. An email whose idempotency key was already accepted, and
+// hasn't failed, isn't queued again.
+func (s *IngestService) Send(ctx context.Context, email Email) (string, error) {
+	if s.accepted(email.IdempotencyKey) {
+		return email.IdempotencyKey, nil
+	}
+
+	record, err := s.record(ctx, email)
+	if err != nil {
+		return "", err
+	}
+	if err = s.enqueue(ctx, []models.Record{record}); err != nil {
+		return "", err
+	}
+	return string(record.Key), nil
+}
+
+// SendBatch validates and renders every email of the batch and accepts them
+// all to be sent, or none of them when any is invalid. The validation errors
+// are reported per email, e.g. emails.2.user.mail_id. The emails whose
+// idempotency key was already accepted are skipped, as by Send.
+func (s *IngestService) SendBatch(ctx context.Context, emails []Email) ([]string, error) {
+	if len(emails) == 0 {
+		return nil, errors.EmptyParamErr("emails")
+	}
+
+	ve := errors.ValidationErrs()
+	if len(emails) > s.maxBatch {
+		ve.Add("emails", fmt.Sprintf("cannot have more than %d emails", s.maxBatch))
+		return nil, errors.ValidationFailedErr(ve.Err())
+	}
+
+	ids := make([]string, len(emails))
+	records := make([]models.Record, 0, len(emails))
+	for i, email := range emails {
+		// duplicates of the batch are only queued once too
+		key := email.IdempotencyKey
+		if key != "" && (s.accepted(key) || slices.Contains(ids[:i], key)) {
+			ids[i] = key
+			continue
+		}
+
+		record, err := s.record(ctx, email)
+		if err != nil {
+			if !errors.IsPermanent(err) {
+				return nil, err
+			}
+			ve.AddErr(fmt.Sprintf("emails.%d", i), err)
+			continue
+		}
+		ids[i] = string(record.Key)
+		records = append(records, record)
+	}
+	if ve.Len() > 0 {
+		return nil, errors.ValidationFailedErr(ve.Err())
+	}
+
+	if len(records) > 0 {
+		if err := s.enqueue(ctx, records); err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+// Status returns the status of the email.
+func (s *IngestService) Status(id string) (models.EmailStatus, error) {
+	if id == "" {
+		return models.EmailStatus{}, errors.EmptyParamErr("message_id")
+	}
+	status, ok := s.statuses.Get(id)
+	if !ok {
+		return status, errors.E(errors.NotFound, fmt.Sprintf("email %q not found", id))
+	}
+	return status, nil
+}
+
+// Watch calls send with the status of the emails and then every change, until
+// they're all sent or failed or the context is done. Unknown emails are
+// reported as NotFound before anything is sent, and the watch stops waiting
+// for the emails forgotten meanwhile. Changes made in quick succession may be
+// sent as their last state only.
+//
+// In the produce mode, the emails are sent by the instance consuming their
+// partition, which may not be this one, so the watch ends after watchTimeout
+// with the last states sent, e.g. produced.
+func (s *IngestService) Watch(ctx context.Context, ids []string, send func(models.EmailStatus) error) error {
+	if len(ids) == 0 {
+		return errors.EmptyParamErr("message_ids")
+	}
+
+	// registered before reading the statuses, so no change is missed between
+	w, stop := s.statuses.Watch(ids)
+	defer stop()
+
+	var unknown []string
+	pending := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		pending[id] = true
+	}
+	current := s.statuses.List(ids)
+	for id := range pending {
+		if _, ok := current[id]; !ok {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return errors.E(errors.NotFound, fmt.Sprintf("emails %q not found", unknown))
+	}
+
+	var timeout <-chan time.Time
+	if s.watchTimeout > 0 {
+		timer := time.NewTimer(s.watchTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	sent := make(map[string]models.EmailStatus, len(pending))
+	for {
+		for _, id := range ids {
+			status, ok := current[id]
+			if !pending[id] || ok && status == sent[id] {
+				continue
+			}
+			if !ok || status.Done() {
+				delete(pending, id)
+			}
+			if !ok {
+				continue
+			}
+			sent[id] = status
+			if err := send(status); err != nil {
+				return err
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout:
+			return nil
+		case <-w.changed:
+			current = s.statuses.List(ids)
+		}
+	}
+}
+
+// Done records whether the email of the record was sent, it's the pipeline
+// tracker of the records carrying an idempotency key.
+func (s *IngestService) Done(record models.Record, attempts int, err error) {
+	id, ok := record.Header(models.HeaderIdempotencyKey)
+	if !ok || id == "" {
+		return
+	}
+
+	status := models.EmailStatus{MessageID: id, State: models.EmailSent, Attempts: attempts, UpdatedAt: time.Now()}
+	if err != nil {
+		status.State = models.EmailFailed
+		status.Error = errors.Message(err)
+	}
+	s.statuses.Set(status)
+}
+
+// accepted reports whether the email of the idempotency key was accepted and
+// hasn't failed. Only the statuses kept by this instance, for ingest.status_ttl,
+// are known, the processor skips the other duplicates it has seen.
+func (s *IngestService) accepted(key string) bool {
+	if key == "" {
+		return false
+	}
+	status, ok := s.statuses.Get(key)
+	return ok && status.State != models.EmailFailed
+}
+
+// record builds the record of an email and checks it can be sent. The message
+// ID is set as its key and idempotency key.
+func (s *IngestService) record(ctx context.Context, email Email) (models.Record, error) {
+	record := models.Record{
+		Topic:     Topic,
+		Value:     email.Payload,
+		Timestamp: time.Now(),
+		Headers: []models.RecordHeader{
+			{Key: models.HeaderContentType, Value: []byte("application/json")},
+			{Key: models.HeaderSchemaVersion, Value: []byte("1")},
+		},
+	}
+	if _, err := s.processor.Prepare(ctx, record); err != nil {
+		return record, err
+	}
+
+	id := email.IdempotencyKey
+	if id == "" {
+		id = uuid.NewString()
+	}
+	record.Key = []byte(id)
+	record.Headers = append(record.Headers, models.RecordHeader{Key: models.HeaderIdempotencyKey, Value: []byte(id)})
+	return record, nil
+}
+
+// enqueue hands the records to the queue and marks them queued or produced.
+func (s *IngestService) enqueue(ctx context.Context, records []models.Record) error {
+	// set first, the direct pipeline may be done with the records before
+	// Enqueue returns
+	now := time.Now()
+	for _, record := range records {
+		s.statuses.Set(models.EmailStatus{MessageID: string(record.Key), State: s.state, UpdatedAt: now})
+	}
+
+	if err := s.queue.Enqueue(ctx, records); err != nil {
+		s.logger.Error("failed to enqueue emails", zap.Int("emails", len(records)), zap.Error(err))
+		for _, record := range records {
+			s.statuses.Set(models.EmailStatus{MessageID: string(record.Key), State: models.EmailFailed,
+				Error: errors.Message(err), UpdatedAt: time.Now()})
+		}
+		return err
+	}
+	return nil
+}
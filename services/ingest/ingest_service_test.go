@@ -0,0 +1,245 @@
+package ingest
+
+import (
+	// Go Internal Packages
+	"context"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	// Local Packages
+	config "github.com/satya-ajayy/Emailer/config"
+	errors "github.com/satya-ajayy/Emailer/errors"
+	metrics "github.com/satya-ajayy/Emailer/metrics"
+	models "github.com/satya-ajayy/Emailer/models"
+	processors "github.com/satya-ajayy/Emailer/services/processors"
+	templates "github.com/satya-ajayy/Emailer/templates"
+	alert "github.com/satya-ajayy/Emailer/utils/alert"
+	mailaddr "github.com/satya-ajayy/Emailer/utils/mailaddr"
+
+	// External Packages
+	"go.uber.org/zap"
+)
+
+func newTestService(mode string, maxStatuses int) *IngestService {
+	return NewService(zap.NewNop(), nil, nil, config.Ingest{
+		Mode:         mode,
+		MaxBatch:     10,
+		StatusTTL:    time.Hour,
+		MaxStatuses:  maxStatuses,
+		WatchTimeout: 100 * time.Millisecond,
+	})
+}
+
+func status(id, state string, attempts int) models.EmailStatus {
+	return models.EmailStatus{MessageID: id, State: state, Attempts: attempts, UpdatedAt: time.Now()}
+}
+
+// watch runs Watch in the background and returns the channels of the sent
+// statuses and of its error.
+func watch(ctx context.Context, s *IngestService, ids ...string) (<-chan models.EmailStatus, <-chan error) {
+	sent := make(chan models.EmailStatus, 1000)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- s.Watch(ctx, ids, func(status models.EmailStatus) error {
+			sent <- status
+			return nil
+		})
+		close(sent)
+	}()
+	return sent, errs
+}
+
+func wait(t *testing.T, errs <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-errs:
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("Watch() didn't end")
+		return nil
+	}
+}
+
+func TestWatchUnknown(t *testing.T) {
+	s := newTestService(config.IngestDirect, 10)
+	s.statuses.Set(status("a", models.EmailQueued, 0))
+
+	called := false
+	err := s.Watch(context.Background(), []string{"a", "c", "b"}, func(models.EmailStatus) error {
+		called = true
+		return nil
+	})
+	if errors.KindOf(err) != errors.NotFound || called {
+		t.Fatalf("Watch() error = %v after sending %v, want NotFound before sending", err, called)
+	}
+	if want := `emails ["b" "c"] not found`; errors.Message(err) != want {
+		t.Errorf("Watch() error = %q, want %q", errors.Message(err), want)
+	}
+}
+
+func TestWatchBurst(t *testing.T) {
+	s := newTestService(config.IngestDirect, 10)
+	s.statuses.Set(status("a", models.EmailQueued, 0))
+	s.statuses.Set(status("b", models.EmailQueued, 0))
+
+	// the watcher is slower than the changes, the last states still end it
+	block := make(chan struct{})
+	var sent []models.EmailStatus
+	errs := make(chan error, 1)
+	go func() {
+		errs <- s.Watch(context.Background(), []string{"a", "b"}, func(status models.EmailStatus) error {
+			<-block
+			sent = append(sent, status)
+			return nil
+		})
+	}()
+	for i := 1; i <= 100; i++ {
+		s.statuses.Set(status("a", models.EmailQueued, i))
+	}
+	s.statuses.Set(status("a", models.EmailSent, 101))
+	s.statuses.Set(status("b", models.EmailFailed, 3))
+	close(block)
+
+	if err := wait(t, errs); err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	last := map[string]models.EmailStatus{}
+	for _, status := range sent {
+		last[status.MessageID] = status
+	}
+	if last["a"].State != models.EmailSent || last["a"].Attempts != 101 || last["b"].State != models.EmailFailed {
+		t.Errorf("last sent statuses = %+v, want a sent and b failed", last)
+	}
+}
+
+func TestWatchForgotten(t *testing.T) {
+	s := newTestService(config.IngestDirect, 2)
+	s.statuses.Set(status("a", models.EmailQueued, 0))
+	s.statuses.Set(status("b", models.EmailQueued, 0))
+
+	sent, errs := watch(context.Background(), s, "a", "b")
+	<-sent
+	<-sent
+	// a is forgotten to make room, b is sent
+	s.statuses.Set(status("c", models.EmailQueued, 0))
+	s.statuses.Set(status("b", models.EmailSent, 1))
+
+	if err := wait(t, errs); err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	for status := range sent {
+		if status.MessageID != "b" || status.State != models.EmailSent {
+			t.Errorf("sent %+v, want b sent", status)
+		}
+	}
+}
+
+func TestWatchTimeout(t *testing.T) {
+	tests := []struct {
+		mode  string
+		ended bool
+	}{
+		{mode: config.IngestProduce, ended: true},
+		{mode: config.IngestDirect, ended: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			s := newTestService(tt.mode, 10)
+			s.statuses.Set(status("a", s.state, 0))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+			defer cancel()
+			sent, errs := watch(ctx, s, "a")
+			err := wait(t, errs)
+			if ended := err == nil; ended != tt.ended {
+				t.Errorf("Watch() error = %v, want ended by the timeout %v", err, tt.ended)
+			}
+			if status := <-sent; status.State != s.state {
+				t.Errorf("sent %+v, want %s", status, s.state)
+			}
+		})
+	}
+}
+
+func TestWatchDuplicates(t *testing.T) {
+	s := newTestService(config.IngestDirect, 10)
+	s.statuses.Set(status("a", models.EmailSent, 1))
+
+	sent, errs := watch(context.Background(), s, "a", "a")
+	if err := wait(t, errs); err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	var n int
+	for range sent {
+		n++
+	}
+	if n != 1 {
+		t.Errorf("sent %d statuses, want a once", n)
+	}
+}
+
+type queued struct {
+	records []models.Record
+}
+
+func (q *queued) Enqueue(_ context.Context, records []models.Record) error {
+	q.records = append(q.records, records...)
+	return nil
+}
+
+func TestSendAccepted(t *testing.T) {
+	logger := zap.NewNop()
+	registry, err := templates.NewRegistry(logger, config.Templates{Default: "problems", DefaultLocale: "en"},
+		alert.Discard)
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+	processor := processors.NewProcessor(logger, config.Credentials{MailID: "emailer@example.com"}, registry,
+		mailaddr.NewValidator(models.Addresses{}, nil), metrics.New("test"),
+		config.Idempotency{MaxKeys: 10, TTL: time.Hour}, config.Schedule{}, nil, false)
+	payload, err := os.ReadFile("../../templates/fixtures/problems/default.json")
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+
+	queue := &queued{}
+	s := NewService(logger, processor, queue, config.Ingest{Mode: config.IngestDirect, MaxBatch: 10,
+		StatusTTL: time.Hour, MaxStatuses: 10})
+	s.statuses.Set(status("failed", models.EmailFailed, 3))
+
+	if _, err = s.Send(context.Background(), Email{Payload: payload, IdempotencyKey: "weekly"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	// retried requests get the same ID without queueing the email again
+	id, err := s.Send(context.Background(), Email{Payload: payload, IdempotencyKey: "weekly"})
+	if err != nil || id != "weekly" {
+		t.Fatalf("Send() again = %q, %v, want weekly", id, err)
+	}
+	if len(queue.records) != 1 {
+		t.Fatalf("queued %d records, want 1", len(queue.records))
+	}
+
+	ids, err := s.SendBatch(context.Background(), []Email{
+		{Payload: payload, IdempotencyKey: "weekly"},
+		{Payload: payload, IdempotencyKey: "failed"},
+		{Payload: payload, IdempotencyKey: "daily"},
+		{Payload: payload, IdempotencyKey: "daily"},
+	})
+	if err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+	if want := []string{"weekly", "failed", "daily", "daily"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("SendBatch() = %v, want %v", ids, want)
+	}
+	var keys []string
+	for _, record := range queue.records[1:] {
+		keys = append(keys, string(record.Key))
+	}
+	// the failed email is sent again, the accepted ones and duplicates aren't
+	if want := []string{"failed", "daily"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("queued %v, want %v", keys, want)
+	}
+}
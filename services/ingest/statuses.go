@@ -0,0 +1,132 @@
+package ingest
+
+import (
+	// Go Internal Packages
+	"container/list"
+	"sync"
+	"time"
+
+	// Local Packages
+	models "github.com/satya-ajayy/Emailer/models"
+)
+
+// statuses keeps the status of the accepted emails for a while and hands their
+// changes to the watchers. Once full, the oldest statuses are forgotten first.
+type statuses struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	max      int
+	entries  map[string]*list.Element
+	order    *list.List
+	watchers map[*watcher]struct{}
+}
+
+// watcher is signaled when the statuses of some emails change. The signals
+// of several changes are merged into one, the watcher then reads the statuses
+// again, so none is missed however slow the watcher is.
+type watcher struct {
+	ids     map[string]bool
+	changed chan struct{}
+}
+
+func newStatuses(max int, ttl time.Duration) *statuses {
+	return &statuses{
+		ttl:      ttl,
+		max:      max,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		watchers: make(map[*watcher]struct{}),
+	}
+}
+
+// Get returns the status of the email.
+func (s *statuses) Get(id string) (models.EmailStatus, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire()
+	elem, ok := s.entries[id]
+	if !ok {
+		return models.EmailStatus{}, false
+	}
+	return elem.Value.(models.EmailStatus), true
+}
+
+// Set stores the status and signals the watchers of the email.
+func (s *statuses) Set(status models.EmailStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[status.MessageID]; ok {
+		s.order.Remove(elem)
+	}
+	s.entries[status.MessageID] = s.order.PushBack(status)
+	s.signal(status.MessageID)
+	for s.order.Len() > s.max {
+		s.remove(s.order.Front())
+	}
+}
+
+// List returns the statuses of the emails by message ID, without the unknown
+// or forgotten ones.
+func (s *statuses) List(ids []string) map[string]models.EmailStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire()
+	current := make(map[string]models.EmailStatus, len(ids))
+	for _, id := range ids {
+		if elem, ok := s.entries[id]; ok {
+			current[id] = elem.Value.(models.EmailStatus)
+		}
+	}
+	return current
+}
+
+// Watch registers a watcher for the changes of the statuses of the emails,
+// stop unregisters it.
+func (s *statuses) Watch(ids []string) (w *watcher, stop func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w = &watcher{ids: make(map[string]bool, len(ids)), changed: make(chan struct{}, 1)}
+	for _, id := range ids {
+		w.ids[id] = true
+	}
+	s.watchers[w] = struct{}{}
+
+	return w, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.watchers, w)
+	}
+}
+
+// signal tells the watchers of the email its status changed or was forgotten.
+func (s *statuses) signal(id string) {
+	for w := range s.watchers {
+		if !w.ids[id] {
+			continue
+		}
+		select {
+		case w.changed <- struct{}{}:
+		default:
+			// already signaled, the watcher hasn't read the statuses yet
+		}
+	}
+}
+
+// expire forgets the statuses not updated within the TTL.
+func (s *statuses) expire() {
+	cutoff := time.Now().Add(-s.ttl)
+	for front := s.order.Front(); front != nil && front.Value.(models.EmailStatus).UpdatedAt.Before(cutoff); front = s.order.Front() {
+		s.remove(front)
+	}
+}
+
+func (s *statuses) remove(elem *list.Element) {
+	id := elem.Value.(models.EmailStatus).MessageID
+	s.order.Remove(elem)
+	delete(s.entries, id)
+	s.signal(id)
+}
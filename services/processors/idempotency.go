@@ -0,0 +1,71 @@
+package processors
+
+import (
+	// Go Internal Packages
+	"container/list"
+	"sync"
+	"time"
+)
+
+// seenKeys remembers the idempotency keys of the records already sent for a
+// while, so redelivered or duplicated records aren't sent twice. Once full,
+// the oldest keys are forgotten first.
+type seenKeys struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	max   int
+	keys  map[string]*list.Element
+	order *list.List
+}
+
+type seenKey struct {
+	key string
+	at  time.Time
+}
+
+func newSeenKeys(max int, ttl time.Duration) *seenKeys {
+	return &seenKeys{
+		ttl:   ttl,
+		max:   max,
+		keys:  make(map[string]*list.Element),
+		order: list.New(),
+	}
+}
+
+// Seen reports whether the key was added within the TTL.
+func (s *seenKeys) Seen(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire()
+	_, ok := s.keys[key]
+	return ok
+}
+
+// Add remembers the key.
+func (s *seenKeys) Add(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.keys[key]; ok {
+		s.order.Remove(elem)
+	}
+	s.keys[key] = s.order.PushBack(seenKey{key: key, at: time.Now()})
+
+	for s.order.Len() > s.max {
+		s.remove(s.order.Front())
+	}
+}
+
+// expire forgets the keys older than the TTL.
+func (s *seenKeys) expire() {
+	cutoff := time.Now().Add(-s.ttl)
+	for front := s.order.Front(); front != nil && front.Value.(seenKey).at.Before(cutoff); front = s.order.Front() {
+		s.remove(front)
+	}
+}
+
+func (s *seenKeys) remove(elem *list.Element) {
+	s.order.Remove(elem)
+	delete(s.keys, elem.Value.(seenKey).key)
+}
@@ -2,67 +2,326 @@ package processors
 
 import (
 	// Go Internal Packages
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"html/template"
+	"io"
+	"mime"
+	"net/mail"
+	"sync/atomic"
+	"time"
 
 	// Local Packages
-	config "emailer/config"
-	models "emailer/models"
+	config "github.com/satya-ajayy/Emailer/config"
+	errors "github.com/satya-ajayy/Emailer/errors"
+	metrics "github.com/satya-ajayy/Emailer/metrics"
+	models "github.com/satya-ajayy/Emailer/models"
+	pipeline "github.com/satya-ajayy/Emailer/pipeline"
+	templates "github.com/satya-ajayy/Emailer/templates"
+	tracing "github.com/satya-ajayy/Emailer/tracing"
+	css "github.com/satya-ajayy/Emailer/utils/css"
+	mailaddr "github.com/satya-ajayy/Emailer/utils/mailaddr"
+	plaintext "github.com/satya-ajayy/Emailer/utils/plaintext"
 
 	// External Packages
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"gopkg.in/gomail.v2"
 )
 
+var tracer = tracing.Tracer("github.com/satya-ajayy/Emailer/processor")
+
+// unknownTemplate labels the metrics of records that couldn't be decoded or
+// name a template the registry doesn't have, the names come from the records
+// so they can't be labels as is.
+const unknownTemplate = "unknown"
+
+// supportedSchemaVersions are the record schema versions the processor can
+// handle, records without the schema-version header are taken as version 1.
+var supportedSchemaVersions = map[string]bool{"1": true}
+
+// errDuplicate reports a record whose idempotency key was already sent.
+var errDuplicate = errors.NewError("duplicate record")
+
 type MailProcessor struct {
-	logger *zap.Logger
-	creds  config.Credentials
+	logger    *zap.Logger
+	templates *templates.Registry
+	addresses *mailaddr.Validator
+	metrics   *metrics.Metrics
+	seen      *seenKeys
+	routes    *Routes
+	maxDelay  time.Duration
+	settings  atomic.Pointer[settings]
+	// dialer returns the dialer of the SMTP server, NewDialer but in tests
+	dialer func(creds config.Credentials) *gomail.Dialer
+}
+
+// settings are the parts of the processor config that can be reloaded.
+type settings struct {
+	creds     config.Credentials
+	inlineCSS bool
+}
+
+func NewProcessor(logger *zap.Logger, creds config.Credentials, registry *templates.Registry,
+	addresses *mailaddr.Validator, metrics *metrics.Metrics, idempotency config.Idempotency,
+	schedule config.Schedule, routes *Routes, inlineCSS bool) *MailProcessor {
+	p := &MailProcessor{
+		logger:    logger,
+		templates: registry,
+		addresses: addresses,
+		metrics:   metrics,
+		seen:      newSeenKeys(idempotency.MaxKeys, idempotency.TTL),
+		routes:    routes,
+		maxDelay:  schedule.MaxDelay,
+		dialer:    NewDialer,
+	}
+	p.Apply(creds, inlineCSS)
+	return p
 }
 
-func NewProcessor(logger *zap.Logger, creds config.Credentials) *MailProcessor {
-	return &MailProcessor{logger: logger, creds: creds}
+// Apply switches the sender credentials and CSS inlining for the next records.
+func (p *MailProcessor) Apply(creds config.Credentials, inlineCSS bool) {
+	p.settings.Store(&settings{creds: creds, inlineCSS: inlineCSS})
 }
 
 func (p *MailProcessor) ProcessRecord(ctx context.Context, record models.Record) error {
+	route := p.routes.match(record.Topic)
+	template, err := p.processRecord(ctx, record, route)
+	var later *pipeline.Deferred
+	if errors.As(err, &later) {
+		return err
+	}
+	if errors.Is(err, errDuplicate) {
+		p.logger.Info("skipping duplicate record", zap.String("record", record.Position()))
+		p.metrics.Skipped("duplicate")
+		p.metrics.RouteEmail(route.Name, "duplicate")
+		return nil
+	}
+	if err != nil {
+		p.metrics.EmailFailed(template, err)
+		p.metrics.RouteEmail(route.Name, "failed")
+		return err
+	}
+
+	p.metrics.EmailSent(template)
+	p.metrics.RouteEmail(route.Name, "sent")
+	if key, ok := record.Header(models.HeaderIdempotencyKey); ok && key != "" {
+		p.seen.Add(key)
+	}
+	return nil
+}
+
+// Priority returns the priority of the route of the record, the pipeline
+// processes the records of a batch by descending priority.
+func (p *MailProcessor) Priority(record models.Record) int {
+	return p.routes.Priority(record)
+}
+
+// checkHeaders rejects the records the processor can't handle according to
+// their headers, and the ones already sent.
+func (p *MailProcessor) checkHeaders(record models.Record) error {
+	if contentType, ok := record.Header(models.HeaderContentType); ok {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || mediaType != "application/json" {
+			return errors.E(errors.Invalid, fmt.Sprintf("unsupported content type %q", contentType))
+		}
+	}
+
+	if version, ok := record.Header(models.HeaderSchemaVersion); ok && !supportedSchemaVersions[version] {
+		return errors.E(errors.Invalid, fmt.Sprintf("unsupported schema version %q", version))
+	}
+
+	if key, ok := record.Header(models.HeaderIdempotencyKey); ok && key != "" && p.seen.Seen(key) {
+		return errDuplicate
+	}
+	return nil
+}
+
+// Prepare validates the record and renders its email without sending it,
+// e.g. for dry runs.
+func (p *MailProcessor) Prepare(ctx context.Context, record models.Record) (*models.Email, error) {
+	_, email, err := p.prepare(ctx, record, p.routes.match(record.Topic))
+	return email, err
+}
+
+// processRecord sends the email of the record and returns the name of the
+// template it was rendered with. Emails scheduled later are deferred, not
+// waited for.
+func (p *MailProcessor) processRecord(ctx context.Context, record models.Record, route *route) (string, error) {
+	template, email, err := p.prepare(ctx, record, route)
+	if err != nil {
+		return template, err
+	}
+	if email.SendAt != nil && email.SendAt.After(time.Now()) {
+		return template, &pipeline.Deferred{Until: *email.SendAt}
+	}
+
+	m := gomail.NewMessage()
+	m.SetHeaders(email.Headers)
+	m.SetBody("text/plain", email.Text)
+	m.AddAlternative("text/html", email.HTML)
+	for _, attachment := range email.Attachments {
+		attach(m, attachment)
+	}
+	d := p.dialer(p.settings.Load().creds)
+
+	start := time.Now()
+	err = p.send(ctx, d, m)
+	p.metrics.ObserveSMTP(time.Since(start))
+	if err != nil {
+		return template, &models.SMTPError{Err: err}
+	}
+	return template, nil
+}
+
+// prepare checks, validates and renders the record into its email the way of
+// its route, returning the name of the template it was rendered with as the
+// metrics label, unknownTemplate when it doesn't exist.
+func (p *MailProcessor) prepare(ctx context.Context, record models.Record, route *route) (string, *models.Email, error) {
+	if err := p.checkHeaders(record); err != nil {
+		return unknownTemplate, nil, err
+	}
+
+	_, span := tracer.Start(ctx, "unmarshal")
 	var userLinks models.UserLinks
 	err := json.Unmarshal(record.Value, &userLinks)
 	if err != nil {
-		return fmt.Errorf("error unmarshalling JSON: %v", err)
+		err = errors.E(errors.Invalid, "error unmarshalling JSON", err)
+	}
+	tracing.End(span, err)
+	if err != nil {
+		return unknownTemplate, nil, err
 	}
 
-	m := gomail.NewMessage()
-	m.SetHeader("From", p.creds.MailID)
-	m.SetHeader("To", userLinks.User.MailID)
-	m.SetHeader("Subject", "!!! Checkout Our New Problems !!!")
-	body, err := p.GetHTML(userLinks)
+	if route.Template != "" {
+		userLinks.Template = route.Template
+	}
+	template := p.templates.Resolve(userLinks.Template)
+	label := template
+	if !p.templates.Has(template) {
+		label = unknownTemplate
+	}
+	schema := template
+	if route.Schema != "" {
+		schema = route.Schema
+	}
+	validateCtx, span := tracer.Start(ctx, "validate", trace.WithAttributes(attribute.String("template", template),
+		attribute.String("route", route.Name)))
+	err = p.templates.Validate(schema, record.Value)
+	if err == nil {
+		err = userLinks.Validate(time.Now(), p.maxDelay)
+	}
+	if err == nil {
+		var to *mail.Address
+		if to, err = p.addresses.Normalize(validateCtx, "user.mail_id", userLinks.User.MailID); err == nil {
+			userLinks.User.MailID = to.String()
+		}
+	}
+	tracing.End(span, err)
 	if err != nil {
-		return fmt.Errorf("error getting HTML: %v", err)
+		return label, nil, err
 	}
 
-	m.SetBody("text/html", body)
-	d := gomail.NewDialer("smtp.gmail.com", 587, p.creds.MailID, p.creds.Password)
-	if err = d.DialAndSend(m); err != nil {
-		return fmt.Errorf("could not send email: %v", err)
+	_, span = tracer.Start(ctx, "render", trace.WithAttributes(attribute.String("template", template)))
+	start := time.Now()
+	email, err := p.compose(userLinks, route.Sender)
+	tracing.End(span, err)
+	if err != nil {
+		return label, nil, err
 	}
-	return nil
+	p.metrics.ObserveRender(label, time.Since(start))
+	return label, email, nil
+}
+
+// attach adds the attachment to the message.
+func attach(m *gomail.Message, attachment models.Attachment) {
+	settings := []gomail.FileSetting{
+		gomail.SetCopyFunc(func(w io.Writer) error {
+			_, err := w.Write(attachment.Content)
+			return err
+		}),
+	}
+	if attachment.ContentType != "" {
+		settings = append(settings, gomail.SetHeader(map[string][]string{"Content-Type": {attachment.ContentType}}))
+	}
+	m.Attach(attachment.Filename, settings...)
+}
+
+// NewDialer returns the dialer of the SMTP server the emails are sent through.
+func NewDialer(creds config.Credentials) *gomail.Dialer {
+	return gomail.NewDialer("smtp.gmail.com", 587, creds.MailID, creds.Password.Value())
+}
+
+// send dials the SMTP server and sends the message, tracing both steps.
+func (p *MailProcessor) send(ctx context.Context, d *gomail.Dialer, m *gomail.Message) error {
+	_, span := tracer.Start(ctx, "smtp.dial", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("server.address", d.Host), attribute.Int("server.port", d.Port)))
+	sender, err := d.Dial()
+	tracing.End(span, err)
+	if err != nil {
+		return err
+	}
+	defer sender.Close()
+
+	_, span = tracer.Start(ctx, "smtp.send", trace.WithSpanKind(trace.SpanKindClient))
+	err = gomail.Send(sender, m)
+	tracing.End(span, err)
+	return err
+}
+
+// Compose renders the template selected by the data into an email with its
+// HTML and text parts and headers, without sending it.
+func (p *MailProcessor) Compose(userLinks models.UserLinks) (*models.Email, error) {
+	return p.compose(userLinks, config.Sender{})
 }
 
-func (p *MailProcessor) GetHTML(userLinks models.UserLinks) (string, error) {
-	problemsTemplate, err := template.New("problems.html").
-		Funcs(template.FuncMap{"call": func(f interface{}) interface{} { return f.(func() string)() }}).
-		ParseFiles("templates/problems.html")
+// compose renders the email like Compose, with the sender fields that are set
+// overriding the template's from name and the credentials' address.
+func (p *MailProcessor) compose(userLinks models.UserLinks, sender config.Sender) (*models.Email, error) {
+	rendered, err := p.templates.Render(userLinks.Template, userLinks)
 	if err != nil {
-		return "", fmt.Errorf("template parse error: %v", err)
+		return nil, fmt.Errorf("error rendering email: %v", err)
+	}
+
+	settings := p.settings.Load()
+	body := rendered.HTML
+	if settings.inlineCSS {
+		if body, err = css.Inline(body); err != nil {
+			return nil, fmt.Errorf("error inlining CSS: %v", err)
+		}
 	}
 
-	var body bytes.Buffer
-	err = problemsTemplate.Execute(&body, userLinks)
+	text, err := plaintext.FromHTML(body)
 	if err != nil {
-		return "", fmt.Errorf("template execution error: %v", err)
+		return nil, fmt.Errorf("error building text part: %v", err)
 	}
 
-	return body.String(), nil
+	name, address := rendered.FromName, settings.creds.MailID
+	if sender.Name != "" {
+		name = sender.Name
+	}
+	if sender.MailID != "" {
+		address = sender.MailID
+	}
+	from := (&mail.Address{Name: name, Address: address}).String()
+	headers := map[string][]string{
+		"From":    {from},
+		"To":      {userLinks.User.MailID},
+		"Subject": {rendered.Subject},
+	}
+	if sender.ReplyTo != "" {
+		headers["Reply-To"] = []string{sender.ReplyTo}
+	}
+	return &models.Email{
+		From:        from,
+		To:          userLinks.User.MailID,
+		Subject:     rendered.Subject,
+		Preheader:   rendered.Preheader,
+		HTML:        body,
+		Text:        text,
+		Headers:     headers,
+		Attachments: userLinks.Attachments,
+		SendAt:      userLinks.SendAt,
+	}, nil
 }
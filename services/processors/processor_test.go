@@ -0,0 +1,82 @@
+package processors
+
+import (
+	// Go Internal Packages
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	// Local Packages
+	config "github.com/satya-ajayy/Emailer/config"
+	metrics "github.com/satya-ajayy/Emailer/metrics"
+	models "github.com/satya-ajayy/Emailer/models"
+	templates "github.com/satya-ajayy/Emailer/templates"
+	mailaddr "github.com/satya-ajayy/Emailer/utils/mailaddr"
+
+	// External Packages
+	"go.uber.org/zap"
+)
+
+// scrape returns the metrics in the Prometheus text format.
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	return string(body)
+}
+
+func TestTemplateLabel(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		label string
+	}{
+		{name: "unknown template", value: `{"template":"attacker-chosen-1234","user":{"mail_id":"ada@example.com"}}`,
+			label: `template="unknown"`},
+		{name: "invalid JSON", value: `{`, label: `template="unknown"`},
+		{name: "known template", value: `{"template":"problems","user":{"mail_id":"ada"}}`,
+			label: `template="problems"`},
+	}
+
+	logger := zap.NewNop()
+	registry, err := templates.NewRegistry(logger, config.Templates{Default: "problems", DefaultLocale: "en"},
+		nopNotifier{})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := metrics.New("test")
+			processor := NewProcessor(logger, config.Credentials{MailID: "emailer@example.com"}, registry,
+				mailaddr.NewValidator(config.Addresses{}, nil), m, config.Idempotency{MaxKeys: 10, TTL: time.Hour},
+				config.Schedule{}, nil, false)
+
+			record := models.Record{Topic: "emails", Value: []byte(tt.value)}
+			if err := processor.ProcessRecord(context.Background(), record); err == nil {
+				t.Fatal("ProcessRecord() error = nil")
+			}
+			scraped := scrape(t, m)
+			if !strings.Contains(scraped, "test_emails_failed_total{kind=\"invalid input\","+tt.label+"} 1") {
+				t.Errorf("failed emails not counted with %s:\n%s", tt.label, grep(scraped, "emails_failed"))
+			}
+			if strings.Contains(scraped, "attacker") {
+				t.Error("the record's template name is a label")
+			}
+		})
+	}
+}
+
+// grep returns the lines containing s.
+func grep(text, s string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.Contains(line, s) {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
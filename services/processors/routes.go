@@ -0,0 +1,86 @@
+package processors
+
+import (
+	// Go Internal Packages
+	"fmt"
+	"regexp"
+
+	// Local Packages
+	config "github.com/satya-ajayy/Emailer/config"
+	models "github.com/satya-ajayy/Emailer/models"
+	templates "github.com/satya-ajayy/Emailer/templates"
+)
+
+// route is how the emails of the records of a topic are sent.
+type route struct {
+	config.Route
+	pattern *regexp.Regexp
+}
+
+// matches reports whether the records of the topic take the route.
+func (r *route) matches(topic string) bool {
+	if r.pattern != nil {
+		return r.pattern.MatchString(topic)
+	}
+	return r.Topic == topic
+}
+
+// Routes maps the topics of the records to their routes, the records of the
+// topics matching no route take the default one, sent as the record says.
+type Routes struct {
+	routes []*route
+}
+
+// defaultRoute is the route of the records of kafka.topic, the API, the
+// commands and the topics matching no route.
+var defaultRoute = &route{Route: config.Route{Name: config.DefaultRoute}}
+
+// NewRoutes compiles the routes of the config.
+func NewRoutes(conf []config.Route) (*Routes, error) {
+	routes := &Routes{}
+	for _, rc := range conf {
+		r := &route{Route: rc}
+		if rc.Pattern != "" {
+			pattern, err := regexp.Compile(rc.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern of route %s: %v", rc.Name, err)
+			}
+			r.pattern = pattern
+		}
+		routes.routes = append(routes.routes, r)
+	}
+	return routes, nil
+}
+
+// Check reports the routes whose template or schema template isn't in the
+// registry.
+func (r *Routes) Check(registry *templates.Registry) error {
+	for _, route := range r.routes {
+		if route.Template != "" && !registry.Has(route.Template) {
+			return fmt.Errorf("template %q of route %s not found", route.Template, route.Name)
+		}
+		if route.Schema != "" && !registry.Has(route.Schema) {
+			return fmt.Errorf("schema template %q of route %s not found", route.Schema, route.Name)
+		}
+	}
+	return nil
+}
+
+// match returns the route of the records of the topic, the default one for
+// nil Routes.
+func (r *Routes) match(topic string) *route {
+	if r == nil {
+		return defaultRoute
+	}
+	for _, route := range r.routes {
+		if route.matches(topic) {
+			return route
+		}
+	}
+	return defaultRoute
+}
+
+// Priority returns the priority of the route of the record.
+func (r *Routes) Priority(record models.Record) int {
+	return r.match(record.Topic).Priority
+}
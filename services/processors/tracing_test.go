@@ -0,0 +1,245 @@
+package processors
+
+import (
+	// Go Internal Packages
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	// Local Packages
+	config "github.com/satya-ajayy/Emailer/config"
+	metrics "github.com/satya-ajayy/Emailer/metrics"
+	models "github.com/satya-ajayy/Emailer/models"
+	pipeline "github.com/satya-ajayy/Emailer/pipeline"
+	templates "github.com/satya-ajayy/Emailer/templates"
+	tracing "github.com/satya-ajayy/Emailer/tracing"
+	alert "github.com/satya-ajayy/Emailer/utils/alert"
+	mailaddr "github.com/satya-ajayy/Emailer/utils/mailaddr"
+
+	// External Packages
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"gopkg.in/gomail.v2"
+)
+
+type nopNotifier struct{}
+
+func (nopNotifier) Notify(context.Context, alert.Event) error { return nil }
+
+// smtpServer accepts every message sent to it, without TLS nor auth, and
+// returns its address.
+func smtpServer(t *testing.T) (string, int) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn)
+		}
+	}()
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func serveSMTP(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		switch verb := strings.ToUpper(strings.Fields(line + " ")[0]); verb {
+		case "EHLO", "HELO", "MAIL", "RCPT", "RSET", "NOOP":
+			reply("250 ok")
+		case "DATA":
+			reply("354 go ahead")
+			for {
+				if line, err = r.ReadString('\n'); err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+			}
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+// polledSource traces its polls and commits the way the Kafka consumer does,
+// so the pipeline can be run without a broker.
+type polledSource struct {
+	*pipeline.Channel
+}
+
+func (s polledSource) Next(ctx context.Context) (pipeline.Batch, error) {
+	_, span := tracer.Start(ctx, "kafka.poll")
+	batch, err := s.Channel.Next(ctx)
+	span.End()
+	batch.Poll = span.SpanContext()
+	return batch, err
+}
+
+func (s polledSource) Commit(ctx context.Context, batch pipeline.Batch) error {
+	ctx, span := tracer.Start(ctx, "kafka.commit", trace.WithLinks(trace.Link{SpanContext: batch.Poll}))
+	err := s.Channel.Commit(ctx, batch)
+	tracing.End(span, err)
+	return err
+}
+
+var (
+	exporter     = tracetest.NewInMemoryExporter()
+	providerOnce sync.Once
+	provider     *sdktrace.TracerProvider
+)
+
+// installProvider installs the provider exporting to exporter once, as the
+// tracers of the packages stick to the first global provider.
+func installProvider() {
+	providerOnce.Do(func() { provider = tracing.NewProvider("emailer-test", exporter, 1) })
+}
+
+func TestRecordSpans(t *testing.T) {
+	installProvider()
+	exporter.Reset()
+
+	logger := zap.NewNop()
+	registry, err := templates.NewRegistry(logger, config.Templates{Default: "problems", DefaultLocale: "en"},
+		nopNotifier{})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+	processor := NewProcessor(logger, config.Credentials{MailID: "emailer@example.com"}, registry,
+		mailaddr.NewValidator(config.Addresses{}, nil), metrics.New("test"), config.Idempotency{},
+		config.Schedule{MaxDelay: time.Minute}, nil, false)
+	host, port := smtpServer(t)
+	processor.dialer = func(config.Credentials) *gomail.Dialer { return &gomail.Dialer{Host: host, Port: port} }
+
+	value, err := os.ReadFile("../../templates/fixtures/problems/default.json")
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	// the producer's span, continued by the record
+	const traceID, spanID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	records := make(chan models.Record, 2)
+	records <- models.Record{Topic: "emails", Offset: 1, Value: value, Headers: []models.RecordHeader{
+		{Key: "traceparent", Value: []byte("00-" + traceID + "-" + spanID + "-01")},
+	}}
+	records <- models.Record{Topic: "emails", Offset: 2, Value: value}
+	close(records)
+
+	committed := make(chan pipeline.Batch, 1)
+	source := polledSource{pipeline.NewChannel(records, 2, committed)}
+	p := pipeline.New(source, processor, pipeline.Options{MaxAttempts: 1}, metrics.New("test"), logger, nopNotifier{})
+	if err = p.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if stats := p.Stats(); stats.Processed != 2 || stats.Failed != 0 {
+		t.Fatalf("Stats() = %+v, want 2 processed", stats)
+	}
+	if err = provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("ForceFlush() error = %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	byID := make(map[trace.SpanID]tracetest.SpanStub, len(spans))
+	for _, span := range spans {
+		byID[span.SpanContext.SpanID()] = span
+	}
+	process := func(offset int64) tracetest.SpanStub {
+		t.Helper()
+		for _, span := range spans {
+			for _, a := range span.Attributes {
+				if span.Name == "record.process" && a.Key == "messaging.kafka.offset" && a.Value.AsInt64() == offset {
+					return span
+				}
+			}
+		}
+		t.Fatalf("record.process of record %d not found", offset)
+		return tracetest.SpanStub{}
+	}
+	child := func(parent tracetest.SpanStub, name string) tracetest.SpanStub {
+		t.Helper()
+		for _, span := range spans {
+			if span.Name == name && span.Parent.SpanID() == parent.SpanContext.SpanID() {
+				return span
+			}
+		}
+		t.Fatalf("span %s of %s not found", name, parent.Name)
+		return tracetest.SpanStub{}
+	}
+	linked := func(span tracetest.SpanStub, name string) tracetest.SpanStub {
+		t.Helper()
+		if len(span.Links) != 1 || byID[span.Links[0].SpanContext.SpanID()].Name != name {
+			t.Fatalf("%s links = %v, want a %s span", span.Name, span.Links, name)
+		}
+		return byID[span.Links[0].SpanContext.SpanID()]
+	}
+
+	var commit tracetest.SpanStub
+	for _, span := range spans {
+		if span.Name == "kafka.commit" {
+			commit = span
+		}
+	}
+	poll := linked(commit, "kafka.poll")
+
+	for _, offset := range []int64{1, 2} {
+		record := process(offset)
+		if got := linked(record, "kafka.poll"); got.SpanContext.SpanID() != poll.SpanContext.SpanID() {
+			t.Errorf("record %d links to another poll than the committed one", offset)
+		}
+
+		// the record continues the producer's trace when it carries one
+		if offset == 1 {
+			if got := record.SpanContext.TraceID().String(); got != traceID {
+				t.Errorf("record.process trace = %s, want %s", got, traceID)
+			}
+			if got := record.Parent.SpanID().String(); got != spanID || !record.Parent.IsRemote() {
+				t.Errorf("record.process parent = %s, want the remote %s", got, spanID)
+			}
+		} else if record.Parent.IsValid() || record.SpanContext.TraceID() == poll.SpanContext.TraceID() {
+			t.Errorf("record.process of a record without traceparent has parent %v", record.Parent)
+		}
+
+		chain := []tracetest.SpanStub{poll}
+		for _, name := range []string{"unmarshal", "validate", "render", "smtp.dial", "smtp.send"} {
+			chain = append(chain, child(record, name))
+		}
+		chain = append(chain, commit)
+
+		for i, span := range chain {
+			if span.Status.Code == codes.Error {
+				t.Errorf("%s of record %d failed: %s", span.Name, offset, span.Status.Description)
+			}
+			if i > 0 && span.StartTime.Before(chain[i-1].EndTime) {
+				t.Errorf("%s of record %d started before %s ended", span.Name, offset, chain[i-1].Name)
+			}
+		}
+	}
+}
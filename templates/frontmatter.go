@@ -0,0 +1,47 @@
+package templates
+
+import (
+	// Go Internal Packages
+	"fmt"
+	"strings"
+
+	// External Packages
+	"gopkg.in/yaml.v3"
+)
+
+// frontMatterDelim opens and closes the front-matter block at the top of a
+// template file.
+const frontMatterDelim = "---"
+
+// FrontMatter holds the header templates declared at the top of a page
+// template. Each value is itself a template evaluated against the same data
+// as the body.
+type FrontMatter struct {
+	Subject   string `yaml:"subject"`
+	Preheader string `yaml:"preheader"`
+	FromName  string `yaml:"from_name"`
+}
+
+// splitFrontMatter separates the front-matter block from the template body.
+// Templates without a front-matter block return an empty FrontMatter.
+func splitFrontMatter(src string) (FrontMatter, string, error) {
+	var fm FrontMatter
+
+	trimmed := strings.TrimLeft(src, "\r\n")
+	if !strings.HasPrefix(trimmed, frontMatterDelim+"\n") && !strings.HasPrefix(trimmed, frontMatterDelim+"\r\n") {
+		return fm, src, nil
+	}
+
+	rest := trimmed[strings.Index(trimmed, "\n")+1:]
+	end := strings.Index(rest, "\n"+frontMatterDelim)
+	if end < 0 {
+		return fm, "", fmt.Errorf("front-matter is not closed with %q", frontMatterDelim)
+	}
+
+	if err := yaml.Unmarshal([]byte(rest[:end]), &fm); err != nil {
+		return fm, "", fmt.Errorf("invalid front-matter: %v", err)
+	}
+
+	body := rest[end+len(frontMatterDelim)+1:]
+	return fm, strings.TrimLeft(body, "\r\n"), nil
+}
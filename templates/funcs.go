@@ -0,0 +1,306 @@
+package templates
+
+import (
+	// Go Internal Packages
+	"fmt"
+	"html/template"
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Zoned is implemented by template data that knows the recipient time zone.
+type Zoned interface {
+	TimeZone() string
+}
+
+// currencySymbols maps the ISO 4217 codes to the symbols used by currency.
+// Codes without a symbol are printed as a prefix, e.g. "CHF 10.00".
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"INR": "₹",
+	"JPY": "¥",
+	"BRL": "R$",
+}
+
+// Funcs returns the functions available to all the templates:
+//
+//	date "Jan 2, 2006" .SentAt $   formats a time in the recipient time zone
+//	now                            returns the current time
+//	plural n "problem" "problems"  picks the singular or plural word for n
+//	number 1234.5 2                formats a number with thousands separators
+//	currency 1234.5 "USD"          formats an amount of money, e.g. $1,234.50
+//	truncate 20 .Name              shortens a string to n runes, adding "…"
+//	url "https://x.io" "k" "v"     builds a URL with the given query params
+//	utm .Link "emailer" "email" "digest"   tags a link with the utm params
+//	safeHTML .Trusted              marks a trusted field as safe HTML
+//	safeURL .Trusted               marks a trusted field as a safe URL
+//	default "there" .User.UserName returns the default when the value is empty
+//	slice 1 2 3                    builds a slice from its arguments
+//	dict "k" "v" "k2" "v2"         builds a map from key and value pairs
+//
+// slice replaces the builtin that slices its argument, list is kept as its
+// alias for the templates written before.
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"call":     func(f interface{}) interface{} { return f.(func() string)() },
+		"date":     formatDate,
+		"now":      time.Now,
+		"plural":   plural,
+		"number":   formatNumber,
+		"currency": formatCurrency,
+		"truncate": truncate,
+		"url":      buildURL,
+		"utm":      tagUTM,
+		"safeHTML": func(s string) template.HTML { return template.HTML(s) },
+		"safeURL":  func(s string) template.URL { return template.URL(s) },
+		"default":  defaultValue,
+		"slice":    slice,
+		"list":     slice,
+		"dict":     dict,
+	}
+}
+
+// formatDate formats a time.Time, an RFC 3339 string or a unix timestamp with
+// the given layout. The optional zone is either an IANA time zone name or
+// data implementing Zoned, falling back to UTC.
+func formatDate(layout string, value any, zone ...any) (string, error) {
+	var t time.Time
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		if v == nil {
+			return "", nil
+		}
+		t = *v
+	case string:
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return "", fmt.Errorf("date: %v", err)
+		}
+		t = parsed
+	case int:
+		t = time.Unix(int64(v), 0)
+	case int64:
+		t = time.Unix(v, 0)
+	case float64:
+		t = time.Unix(int64(v), 0)
+	default:
+		return "", fmt.Errorf("date: unsupported value of type %T", value)
+	}
+
+	name := ""
+	if len(zone) > 0 {
+		switch z := zone[0].(type) {
+		case string:
+			name = z
+		case Zoned:
+			name = z.TimeZone()
+		}
+	}
+
+	loc := time.UTC
+	if name != "" {
+		l, err := time.LoadLocation(name)
+		if err != nil {
+			return "", fmt.Errorf("date: %v", err)
+		}
+		loc = l
+	}
+	return t.In(loc).Format(layout), nil
+}
+
+// plural returns singular when n is 1, otherwise plural.
+func plural(n any, singular, plural string) (string, error) {
+	f, err := toFloat(n)
+	if err != nil {
+		return "", fmt.Errorf("plural: %v", err)
+	}
+	if f == 1 {
+		return singular, nil
+	}
+	return plural, nil
+}
+
+// formatNumber formats a number with thousands separators and the given
+// number of decimals (none by default).
+func formatNumber(value any, decimals ...int) (string, error) {
+	f, err := toFloat(value)
+	if err != nil {
+		return "", fmt.Errorf("number: %v", err)
+	}
+
+	d := 0
+	if len(decimals) > 0 {
+		d = decimals[0]
+	}
+	return groupThousands(strconv.FormatFloat(f, 'f', d, 64)), nil
+}
+
+// formatCurrency formats an amount in the given ISO 4217 currency with two
+// decimals (none for JPY).
+func formatCurrency(value any, code string) (string, error) {
+	f, err := toFloat(value)
+	if err != nil {
+		return "", fmt.Errorf("currency: %v", err)
+	}
+
+	code = strings.ToUpper(code)
+	decimals := 2
+	if code == "JPY" {
+		decimals = 0
+	}
+
+	sign := ""
+	if f < 0 {
+		sign, f = "-", math.Abs(f)
+	}
+	amount := groupThousands(strconv.FormatFloat(f, 'f', decimals, 64))
+
+	if symbol, ok := currencySymbols[code]; ok {
+		return sign + symbol + amount, nil
+	}
+	return sign + code + " " + amount, nil
+}
+
+func groupThousands(number string) string {
+	sign := ""
+	if strings.HasPrefix(number, "-") {
+		sign, number = "-", number[1:]
+	}
+
+	integer, fraction, hasFraction := strings.Cut(number, ".")
+	var b strings.Builder
+	for i, r := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+
+	if hasFraction {
+		return sign + b.String() + "." + fraction
+	}
+	return sign + b.String()
+}
+
+// truncate shortens s to at most n runes, ending it with an ellipsis.
+func truncate(n int, s string) string {
+	if n <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:n-1])) + "…"
+}
+
+// buildURL adds the key and value pairs as query params to the base URL.
+func buildURL(base string, pairs ...any) (string, error) {
+	if len(pairs)%2 != 0 {
+		return "", fmt.Errorf("url: params must be key and value pairs")
+	}
+
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("url: %v", err)
+	}
+
+	query := u.Query()
+	for i := 0; i < len(pairs); i += 2 {
+		query.Set(fmt.Sprint(pairs[i]), fmt.Sprint(pairs[i+1]))
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// tagUTM adds the utm_source, utm_medium and utm_campaign params to a link,
+// keeping the ones it already has.
+func tagUTM(link, source, medium, campaign string) (string, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", fmt.Errorf("utm: %v", err)
+	}
+
+	query := u.Query()
+	for key, value := range map[string]string{
+		"utm_source":   source,
+		"utm_medium":   medium,
+		"utm_campaign": campaign,
+	} {
+		if value != "" && query.Get(key) == "" {
+			query.Set(key, value)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// defaultValue returns def when value is nil or the zero value of its type.
+func defaultValue(def, value any) any {
+	if value == nil {
+		return def
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		if v.Len() == 0 {
+			return def
+		}
+	default:
+		if v.IsZero() {
+			return def
+		}
+	}
+	return value
+}
+
+// slice builds a slice from its arguments, e.g. to range over a few values:
+// {{range slice "a" "b"}}.
+func slice(items ...any) []any {
+	return items
+}
+
+// dict builds a map from key and value pairs, handy to pass several values
+// to a partial: {{template "row" dict "Problem" . "User" $.User}}.
+func dict(pairs ...any) (map[string]any, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict: arguments must be key and value pairs")
+	}
+
+	m := make(map[string]any, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: key %v must be a string", pairs[i])
+		}
+		m[key] = pairs[i+1]
+	}
+	return m, nil
+}
+
+func toFloat(value any) (float64, error) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return strconv.ParseFloat(v.String(), 64)
+	default:
+		return 0, fmt.Errorf("%v is not a number", value)
+	}
+}
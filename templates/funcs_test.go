@@ -0,0 +1,306 @@
+package templates
+
+import (
+	// Go Internal Packages
+	"html/template"
+	"strings"
+	"testing"
+	"time"
+)
+
+type zoned string
+
+func (z zoned) TimeZone() string { return string(z) }
+
+func TestFormatDate(t *testing.T) {
+	at := time.Date(2026, 3, 1, 23, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		layout string
+		value  any
+		zone   []any
+		want   string
+		err    bool
+	}{
+		{name: "time in UTC", layout: "Jan 2 15:04", value: at, want: "Mar 1 23:30"},
+		{name: "pointer", layout: "Jan 2 15:04", value: &at, want: "Mar 1 23:30"},
+		{name: "nil pointer", layout: "Jan 2", value: (*time.Time)(nil), want: ""},
+		{name: "RFC 3339 string", layout: "2006-01-02", value: "2026-03-01T23:30:00Z", want: "2026-03-01"},
+		{name: "unix int", layout: time.RFC3339, value: int(at.Unix()), want: "2026-03-01T23:30:00Z"},
+		{name: "unix float", layout: time.RFC3339, value: float64(at.Unix()), want: "2026-03-01T23:30:00Z"},
+		{name: "zone name", layout: "Jan 2 15:04", value: at, zone: []any{"Asia/Kolkata"}, want: "Mar 2 05:00"},
+		{name: "zoned data", layout: "Jan 2 15:04 MST", value: at, zone: []any{zoned("America/Sao_Paulo")},
+			want: "Mar 1 20:30 -03"},
+		{name: "empty zone is UTC", layout: "15:04", value: at, zone: []any{zoned("")}, want: "23:30"},
+		{name: "unknown zone", layout: "15:04", value: at, zone: []any{"Mars/Olympus"}, err: true},
+		{name: "invalid string", layout: "15:04", value: "yesterday", err: true},
+		{name: "unsupported type", layout: "15:04", value: true, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatDate(tt.layout, tt.value, tt.zone...)
+			if (err != nil) != tt.err {
+				t.Fatalf("formatDate() error = %v, want error %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("formatDate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlural(t *testing.T) {
+	tests := []struct {
+		n    any
+		want string
+		err  bool
+	}{
+		{n: 1, want: "problem"},
+		{n: 0, want: "problems"},
+		{n: 2, want: "problems"},
+		{n: int64(1), want: "problem"},
+		{n: 1.0, want: "problem"},
+		{n: 1.5, want: "problems"},
+		{n: "1", want: "problem"},
+		{n: "many", err: true},
+		{n: nil, err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := plural(tt.n, "problem", "problems")
+		if (err != nil) != tt.err {
+			t.Fatalf("plural(%v) error = %v, want error %v", tt.n, err, tt.err)
+		}
+		if got != tt.want {
+			t.Errorf("plural(%v) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		value    any
+		decimals []int
+		want     string
+		err      bool
+	}{
+		{value: 0, want: "0"},
+		{value: 999, want: "999"},
+		{value: 1000, want: "1,000"},
+		{value: 1234567, want: "1,234,567"},
+		{value: -1234567, want: "-1,234,567"},
+		{value: 1234.5, decimals: []int{2}, want: "1,234.50"},
+		{value: 1234.567, decimals: []int{1}, want: "1,234.6"},
+		{value: uint8(200), want: "200"},
+		{value: "12345", want: "12,345"},
+		{value: "n/a", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := formatNumber(tt.value, tt.decimals...)
+		if (err != nil) != tt.err {
+			t.Fatalf("formatNumber(%v) error = %v, want error %v", tt.value, err, tt.err)
+		}
+		if got != tt.want {
+			t.Errorf("formatNumber(%v, %v) = %q, want %q", tt.value, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestFormatCurrency(t *testing.T) {
+	tests := []struct {
+		value any
+		code  string
+		want  string
+		err   bool
+	}{
+		{value: 1234.5, code: "USD", want: "$1,234.50"},
+		{value: 1234.5, code: "usd", want: "$1,234.50"},
+		{value: 0.5, code: "EUR", want: "€0.50"},
+		{value: -1234.5, code: "GBP", want: "-£1,234.50"},
+		{value: 1234.5, code: "JPY", want: "¥1,234"},
+		{value: 99, code: "BRL", want: "R$99.00"},
+		{value: 10, code: "CHF", want: "CHF 10.00"},
+		{value: "free", code: "USD", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := formatCurrency(tt.value, tt.code)
+		if (err != nil) != tt.err {
+			t.Fatalf("formatCurrency(%v, %s) error = %v, want error %v", tt.value, tt.code, err, tt.err)
+		}
+		if got != tt.want {
+			t.Errorf("formatCurrency(%v, %s) = %q, want %q", tt.value, tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		n    int
+		s    string
+		want string
+	}{
+		{n: 10, s: "Two Sum", want: "Two Sum"},
+		{n: 7, s: "Two Sum", want: "Two Sum"},
+		{n: 4, s: "Two Sum", want: "Two…"},
+		{n: 5, s: "Two Sum", want: "Two…"},
+		{n: 0, s: "Two Sum", want: ""},
+		{n: -1, s: "Two Sum", want: ""},
+		{n: 3, s: "日本語のテキスト", want: "日本…"},
+		{n: 4, s: "héllo wörld", want: "hél…"},
+		{n: 2, s: "👋🏽 hi", want: "👋…"},
+	}
+
+	for _, tt := range tests {
+		got := truncate(tt.n, tt.s)
+		if got != tt.want {
+			t.Errorf("truncate(%d, %q) = %q, want %q", tt.n, tt.s, got, tt.want)
+		}
+		if !strings.HasPrefix(tt.s, strings.TrimSuffix(got, "…")) {
+			t.Errorf("truncate(%d, %q) = %q, which cut a rune", tt.n, tt.s, got)
+		}
+	}
+}
+
+func TestBuildURL(t *testing.T) {
+	tests := []struct {
+		base  string
+		pairs []any
+		want  string
+		err   bool
+	}{
+		{base: "https://x.io/p", want: "https://x.io/p"},
+		{base: "https://x.io/p", pairs: []any{"ref", 42}, want: "https://x.io/p?ref=42"},
+		{base: "https://x.io/p?a=1", pairs: []any{"b", "two words"}, want: "https://x.io/p?a=1&b=two+words"},
+		{base: "https://x.io/p?a=1", pairs: []any{"a", "2"}, want: "https://x.io/p?a=2"},
+		{base: "https://x.io/p", pairs: []any{"odd"}, err: true},
+		{base: "://bad", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := buildURL(tt.base, tt.pairs...)
+		if (err != nil) != tt.err {
+			t.Fatalf("buildURL(%s, %v) error = %v, want error %v", tt.base, tt.pairs, err, tt.err)
+		}
+		if got != tt.want {
+			t.Errorf("buildURL(%s, %v) = %q, want %q", tt.base, tt.pairs, got, tt.want)
+		}
+	}
+}
+
+func TestTagUTM(t *testing.T) {
+	tests := []struct {
+		link                     string
+		source, medium, campaign string
+		want                     string
+		err                      bool
+	}{
+		{link: "https://x.io/p", source: "emailer", medium: "email", campaign: "digest",
+			want: "https://x.io/p?utm_campaign=digest&utm_medium=email&utm_source=emailer"},
+		{link: "https://x.io/p?id=1", source: "emailer", medium: "email",
+			want: "https://x.io/p?id=1&utm_medium=email&utm_source=emailer"},
+		{link: "https://x.io/p?utm_source=blog", source: "emailer", medium: "email", campaign: "digest",
+			want: "https://x.io/p?utm_campaign=digest&utm_medium=email&utm_source=blog"},
+		{link: "://bad", source: "emailer", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := tagUTM(tt.link, tt.source, tt.medium, tt.campaign)
+		if (err != nil) != tt.err {
+			t.Fatalf("tagUTM(%s) error = %v, want error %v", tt.link, err, tt.err)
+		}
+		if got != tt.want {
+			t.Errorf("tagUTM(%s) = %q, want %q", tt.link, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultValue(t *testing.T) {
+	tests := []struct {
+		value any
+		want  any
+	}{
+		{value: nil, want: "there"},
+		{value: "", want: "there"},
+		{value: "Ada", want: "Ada"},
+		{value: 0, want: "there"},
+		{value: 3, want: 3},
+		{value: []string{}, want: "there"},
+		{value: map[string]int{}, want: "there"},
+	}
+
+	for _, tt := range tests {
+		if got := defaultValue("there", tt.value); got != tt.want {
+			t.Errorf("defaultValue(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestDict(t *testing.T) {
+	tests := []struct {
+		name  string
+		pairs []any
+		want  map[string]any
+		err   bool
+	}{
+		{name: "empty", want: map[string]any{}},
+		{name: "pairs", pairs: []any{"a", 1, "b", "two"}, want: map[string]any{"a": 1, "b": "two"}},
+		{name: "odd argument count", pairs: []any{"a", 1, "b"}, err: true},
+		{name: "key not a string", pairs: []any{1, "a"}, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := dict(tt.pairs...)
+			if (err != nil) != tt.err {
+				t.Fatalf("dict() error = %v, want error %v", err, tt.err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("dict() = %v, want %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("dict()[%q] = %v, want %v", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+// TestFuncsInTemplates executes the functions through a template, the way
+// the registry uses them, e.g. slice replacing the builtin.
+func TestFuncsInTemplates(t *testing.T) {
+	tests := []struct {
+		src  string
+		data any
+		want string
+	}{
+		{src: `{{range slice "a" "b" "c"}}{{.}}{{end}}`, want: "abc"},
+		{src: `{{range list 1 2}}{{.}}{{end}}`, want: "12"},
+		{src: `{{with dict "Name" "Ada"}}{{.Name}}{{end}}`, want: "Ada"},
+		{src: `{{plural 2 "problem" "problems"}}`, want: "problems"},
+		{src: `{{currency 1234.5 "USD"}}`, want: "$1,234.50"},
+		{src: `{{truncate 4 "Two Sum"}}`, want: "Two…"},
+		{src: `{{default "there" .}}`, data: "", want: "there"},
+		{src: `<a href="{{utm "https://x.io" "emailer" "email" ""}}">`,
+			want: `<a href="https://x.io?utm_medium=email&amp;utm_source=emailer">`},
+		{src: `{{safeHTML "<b>hi</b>"}}`, want: "<b>hi</b>"},
+		{src: `{{"<b>hi</b>"}}`, want: "&lt;b&gt;hi&lt;/b&gt;"},
+	}
+
+	for _, tt := range tests {
+		tmpl, err := template.New("test").Funcs(Funcs()).Parse(tt.src)
+		if err != nil {
+			t.Fatalf("parse %s: %v", tt.src, err)
+		}
+		var b strings.Builder
+		if err = tmpl.Execute(&b, tt.data); err != nil {
+			t.Fatalf("execute %s: %v", tt.src, err)
+		}
+		if b.String() != tt.want {
+			t.Errorf("%s = %q, want %q", tt.src, b.String(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package templates
+
+import (
+	// Go Internal Packages
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Plural forms a message can define, following the CLDR category names.
+const (
+	pluralOne   = "one"
+	pluralFew   = "few"
+	pluralMany  = "many"
+	pluralOther = "other"
+)
+
+// Localized is implemented by template data that knows the recipient locale.
+type Localized interface {
+	Locale() string
+}
+
+// MessageArgs is the data a catalog message is executed against.
+type MessageArgs struct {
+	Data  any
+	Count int
+}
+
+// message holds the parsed plural forms of a catalog entry keyed by form.
+// Entries without plural forms are stored under "other".
+type message map[string]*texttemplate.Template
+
+// Catalog holds the messages of every locale keyed by locale and message key.
+type Catalog struct {
+	defaultLocale string
+	locales       map[string]map[string]message
+}
+
+// Translate returns the message for key in the locale of data, walking the
+// fallback chain (e.g. pt-BR -> pt -> default locale) until it is found. The
+// optional count selects the plural form.
+func (c *Catalog) Translate(key string, data any, count ...int) (string, error) {
+	n := 0
+	if len(count) > 0 {
+		n = count[0]
+	}
+
+	locale := c.defaultLocale
+	if l, ok := data.(Localized); ok && l.Locale() != "" {
+		locale = l.Locale()
+	}
+
+	for _, candidate := range c.fallbacks(locale) {
+		msg, ok := c.locales[candidate][key]
+		if !ok {
+			continue
+		}
+
+		tmpl, ok := msg[pluralForm(candidate, n)]
+		if !ok {
+			tmpl = msg[pluralOther]
+		}
+		if tmpl == nil {
+			return "", fmt.Errorf("message %q in locale %s has no %q form", key, candidate, pluralOther)
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, MessageArgs{Data: data, Count: n}); err != nil {
+			return "", fmt.Errorf("message %q execution error: %v", key, err)
+		}
+		return buf.String(), nil
+	}
+
+	return "", fmt.Errorf("message %q not found for locale %s", key, locale)
+}
+
+// fallbacks returns the locales to look a message up in, from the most
+// specific one down to the default locale.
+func (c *Catalog) fallbacks(locale string) []string {
+	locale = normalizeLocale(locale)
+
+	var chain []string
+	for locale != "" {
+		chain = append(chain, locale)
+		idx := strings.LastIndex(locale, "-")
+		if idx < 0 {
+			break
+		}
+		locale = locale[:idx]
+	}
+	return append(chain, c.defaultLocale)
+}
+
+// loadCatalog reads the <locale>.json message catalogs from the locales
+// directory of every file system, later file systems overriding earlier ones
+// per message key.
+func loadCatalog(defaultLocale string, fsyss ...fs.FS) (*Catalog, error) {
+	c := &Catalog{
+		defaultLocale: normalizeLocale(defaultLocale),
+		locales:       make(map[string]map[string]message),
+	}
+
+	for _, fsys := range fsyss {
+		files, err := fs.Glob(fsys, "locales/*.json")
+		if err != nil {
+			return nil, fmt.Errorf("error listing locales: %v", err)
+		}
+
+		for _, file := range files {
+			content, err := fs.ReadFile(fsys, file)
+			if err != nil {
+				return nil, fmt.Errorf("error reading locale %s: %v", file, err)
+			}
+
+			locale := normalizeLocale(strings.TrimSuffix(path.Base(file), path.Ext(file)))
+			if err = c.add(locale, content); err != nil {
+				return nil, fmt.Errorf("invalid locale %s: %v", file, err)
+			}
+		}
+	}
+
+	return c, nil
+}
+
+func (c *Catalog) add(locale string, content []byte) error {
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return err
+	}
+
+	if c.locales[locale] == nil {
+		c.locales[locale] = make(map[string]message)
+	}
+
+	for key, raw := range entries {
+		forms := make(map[string]string)
+		var single string
+		if err := json.Unmarshal(raw, &single); err == nil {
+			forms[pluralOther] = single
+		} else if err = json.Unmarshal(raw, &forms); err != nil {
+			return fmt.Errorf("message %q must be a string or an object of plural forms", key)
+		}
+
+		msg := make(message, len(forms))
+		for form, text := range forms {
+			tmpl, err := texttemplate.New(key).Parse(text)
+			if err != nil {
+				return fmt.Errorf("message %q: %v", key, err)
+			}
+			msg[form] = tmpl
+		}
+		c.locales[locale][key] = msg
+	}
+
+	return nil
+}
+
+// normalizeLocale lowercases the locale and uses "-" as the separator, so
+// pt_BR and pt-br both resolve to pt-br.
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// pluralForm returns the CLDR plural category of n for the language of the
+// given locale.
+func pluralForm(locale string, n int) string {
+	lang, _, _ := strings.Cut(locale, "-")
+
+	switch lang {
+	case "ja", "ko", "zh", "th", "vi", "id", "ms", "tr":
+		return pluralOther
+	case "fr", "pt", "hi":
+		if n == 0 || n == 1 {
+			return pluralOne
+		}
+		return pluralOther
+	case "ru", "uk", "be", "sr", "hr", "bs":
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return pluralOne
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return pluralFew
+		default:
+			return pluralMany
+		}
+	case "pl":
+		switch {
+		case n == 1:
+			return pluralOne
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return pluralFew
+		default:
+			return pluralMany
+		}
+	default:
+		if n == 1 {
+			return pluralOne
+		}
+		return pluralOther
+	}
+}
@@ -0,0 +1,445 @@
+package templates
+
+import (
+	// Go Internal Packages
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"html"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	texttemplate "text/template"
+	"time"
+
+	// Local Packages
+	config "github.com/satya-ajayy/Emailer/config"
+	errors "github.com/satya-ajayy/Emailer/errors"
+	schemas "github.com/satya-ajayy/Emailer/templates/schemas"
+	alert "github.com/satya-ajayy/Emailer/utils/alert"
+
+	// External Packages
+	"github.com/fsnotify/fsnotify"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"go.uber.org/zap"
+)
+
+// Defaults holds the templates baked into the binary. Files found in the
+// configured templates directory override these by name.
+//
+//go:embed *.html locales/*.json fixtures/*/*.json
+var Defaults embed.FS
+
+// partialPrefix marks files holding shared layouts and partials. Their
+// {{define}} blocks are available to every page template.
+const partialPrefix = "_"
+
+// reloadDelay is how long the watcher waits for a burst of file events
+// (editors usually write a file in several steps) to settle before reloading.
+const reloadDelay = 250 * time.Millisecond
+
+// preheaderTag hides the preheader in the body while keeping it visible to
+// mail clients as the preview text.
+const preheaderTag = `<div style="display:none;max-height:0;overflow:hidden;mso-hide:all;">%s</div>`
+
+// Email is a rendered template ready to be sent.
+type Email struct {
+	Subject   string
+	Preheader string
+	FromName  string
+	HTML      string
+}
+
+// page is a parsed page template along with its header templates and the
+// optional JSON Schema of its data.
+type page struct {
+	body      *template.Template
+	schema    *jsonschema.Schema
+	subject   *texttemplate.Template
+	preheader *texttemplate.Template
+	fromName  *texttemplate.Template
+}
+
+// pageSet is the parsed page templates keyed by name with the defaults they
+// were loaded with, swapped as a whole on reload.
+type pageSet struct {
+	pages         map[string]*page
+	defaultName   string
+	defaultLocale string
+}
+
+// Registry holds the parsed page templates keyed by name.
+type Registry struct {
+	logger    *zap.Logger
+	alerts    alert.Notifier
+	dir       string
+	reloading sync.Mutex
+	pages     atomic.Pointer[pageSet]
+}
+
+// NewRegistry parses the embedded default templates along with the templates
+// in the given directory and returns the registry.
+func NewRegistry(logger *zap.Logger, conf config.Templates, alerts alert.Notifier) (*Registry, error) {
+	r := &Registry{
+		logger: logger,
+		alerts: alerts,
+		dir:    conf.Dir,
+	}
+
+	if err := r.Apply(conf); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload parses all the templates again and swaps them in only if every
+// template parsed successfully, otherwise the current set is kept.
+func (r *Registry) Reload() error {
+	r.reloading.Lock()
+	defer r.reloading.Unlock()
+
+	current := r.pages.Load()
+	return r.reload(current.defaultName, current.defaultLocale)
+}
+
+// Apply reloads the templates with the default template and locale of the
+// config. The templates directory can't be changed without a restart.
+func (r *Registry) Apply(conf config.Templates) error {
+	r.reloading.Lock()
+	defer r.reloading.Unlock()
+
+	return r.reload(conf.Default, conf.DefaultLocale)
+}
+
+func (r *Registry) reload(defaultName, defaultLocale string) error {
+	pages, err := r.load(defaultLocale)
+	if err != nil {
+		return err
+	}
+	if _, ok := pages[defaultName]; !ok {
+		return fmt.Errorf("default template %q not found", defaultName)
+	}
+
+	r.pages.Store(&pageSet{pages: pages, defaultName: defaultName, defaultLocale: defaultLocale})
+	return nil
+}
+
+// Watch reloads the templates whenever a file in the templates directory or
+// its locales changes, the schemas sitting next to the templates included.
+// The fixtures are read on each preview, so they need no reload. It blocks
+// until the context is canceled.
+func (r *Registry) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("error creating templates watcher: %v", err)
+	}
+	defer watcher.Close()
+
+	if err = watcher.Add(r.dir); err != nil {
+		return fmt.Errorf("error watching templates directory %s: %v", r.dir, err)
+	}
+	locales := filepath.Join(r.dir, "locales")
+	if _, err = os.Stat(locales); err == nil {
+		if err = watcher.Add(locales); err != nil {
+			return fmt.Errorf("error watching locales directory %s: %v", locales, err)
+		}
+	}
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-watcher.Events:
+			r.logger.Debug("templates directory changed", zap.String("event", event.String()))
+			timer.Reset(reloadDelay)
+		case err = <-watcher.Errors:
+			r.logger.Error("templates watcher error", zap.Error(err))
+		case <-timer.C:
+			if err = r.Reload(); err != nil {
+				r.logger.Error("failed to reload templates", zap.Error(err))
+				event := alert.NoticeEvent(alert.SourceTemplates, "Template Reload Failed", err.Error(), alert.Warning)
+				if err = r.alerts.Notify(ctx, event); err != nil {
+					r.logger.Error("failed to send alert", zap.Error(err))
+				}
+				continue
+			}
+			r.logger.Info("templates reloaded", zap.Strings("templates", r.Names()))
+		}
+	}
+}
+
+// Render executes the named template and its header templates against the
+// given data, falling back to the default template when name is empty.
+func (r *Registry) Render(name string, data any) (*Email, error) {
+	name = r.Resolve(name)
+
+	p, ok := r.pages.Load().pages[name]
+	if !ok {
+		return nil, fmt.Errorf("template %q not found", name)
+	}
+
+	var err error
+	email := &Email{}
+	if email.Subject, err = execute(p.subject, data); err != nil {
+		return nil, fmt.Errorf("subject execution error: %v", err)
+	}
+	if email.Preheader, err = execute(p.preheader, data); err != nil {
+		return nil, fmt.Errorf("preheader execution error: %v", err)
+	}
+	if email.FromName, err = execute(p.fromName, data); err != nil {
+		return nil, fmt.Errorf("from name execution error: %v", err)
+	}
+
+	var body bytes.Buffer
+	if err = p.body.Execute(&body, data); err != nil {
+		return nil, fmt.Errorf("template execution error: %v", err)
+	}
+	email.HTML = injectPreheader(body.String(), email.Preheader)
+
+	return email, nil
+}
+
+// Validate checks the raw data against the JSON Schema of the named template,
+// falling back to the default template when name is empty. Templates without
+// a schema accept any data.
+func (r *Registry) Validate(name string, data []byte) error {
+	name = r.Resolve(name)
+
+	p, ok := r.pages.Load().pages[name]
+	if !ok {
+		return errors.E(errors.Invalid, fmt.Sprintf("template %q not found", name))
+	}
+	return schemas.Validate(p.schema, data)
+}
+
+// Resolve returns the template name to use for the given name, i.e. the
+// default template when name is empty.
+func (r *Registry) Resolve(name string) string {
+	if name == "" {
+		return r.pages.Load().defaultName
+	}
+	return name
+}
+
+// Has reports whether a page template with the given name exists.
+func (r *Registry) Has(name string) bool {
+	_, ok := r.pages.Load().pages[name]
+	return ok
+}
+
+// Fixture returns the named sample data of a template, stored as
+// fixtures/<template>/<fixture>.json in the templates directory or defaults.
+func (r *Registry) Fixture(name, fixture string) ([]byte, error) {
+	if strings.ContainsAny(name+fixture, `/\.`) {
+		return nil, fmt.Errorf("invalid fixture %q", fixture)
+	}
+
+	file := path.Join("fixtures", name, fixture+".json")
+	fsyss := r.fileSystems()
+	for i := len(fsyss) - 1; i >= 0; i-- {
+		content, err := fs.ReadFile(fsyss[i], file)
+		if err == nil {
+			return content, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("error reading fixture %s: %v", file, err)
+		}
+	}
+	return nil, fs.ErrNotExist
+}
+
+// Fixtures returns the sorted names of the sample data stored for a template
+// in the templates directory and defaults.
+func (r *Registry) Fixtures(name string) ([]string, error) {
+	seen := make(map[string]bool)
+	for _, fsys := range r.fileSystems() {
+		files, err := fs.Glob(fsys, path.Join("fixtures", name, "*.json"))
+		if err != nil {
+			return nil, fmt.Errorf("error listing fixtures: %v", err)
+		}
+		for _, file := range files {
+			seen[strings.TrimSuffix(path.Base(file), ".json")] = true
+		}
+	}
+
+	fixtures := make([]string, 0, len(seen))
+	for fixture := range seen {
+		fixtures = append(fixtures, fixture)
+	}
+	sort.Strings(fixtures)
+	return fixtures, nil
+}
+
+// Names returns the sorted names of all the page templates.
+func (r *Registry) Names() []string {
+	pages := r.pages.Load().pages
+	names := make([]string, 0, len(pages))
+	for name := range pages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// load reads the template sources and message catalogs and parses every
+// page template against the shared partials.
+func (r *Registry) load(defaultLocale string) (map[string]*page, error) {
+	fsyss := r.fileSystems()
+	sources, err := readSources(fsyss...)
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := loadCatalog(defaultLocale, fsyss...)
+	if err != nil {
+		return nil, err
+	}
+	// the default schemas are embedded by the schemas package, in place of
+	// the default templates
+	compiled, err := schemas.Read(append([]fs.FS{schemas.Defaults}, fsyss[1:]...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	funcs := Funcs()
+	funcs["t"] = catalog.Translate
+
+	base := template.New(partialPrefix).Funcs(funcs)
+	for name, src := range sources {
+		if !strings.HasPrefix(name, partialPrefix) {
+			continue
+		}
+		if _, err = base.New(name).Parse(src); err != nil {
+			return nil, fmt.Errorf("template parse error in %s: %v", name, err)
+		}
+	}
+
+	pages := make(map[string]*page)
+	for name, src := range sources {
+		if strings.HasPrefix(name, partialPrefix) {
+			continue
+		}
+
+		p, err := parsePage(base, funcs, name, src)
+		if err != nil {
+			return nil, fmt.Errorf("template parse error in %s: %v", name, err)
+		}
+		p.schema = compiled[name]
+		pages[name] = p
+	}
+
+	for name := range compiled {
+		if _, ok := pages[name]; !ok {
+			return nil, fmt.Errorf("schema %s%s has no template", name, schemas.Suffix)
+		}
+	}
+
+	return pages, nil
+}
+
+// parsePage parses the front-matter and body of a page template.
+func parsePage(base *template.Template, funcs template.FuncMap, name, src string) (*page, error) {
+	fm, body, err := splitFrontMatter(src)
+	if err != nil {
+		return nil, err
+	}
+	if fm.Subject == "" {
+		return nil, fmt.Errorf("front-matter must define a subject")
+	}
+
+	p := &page{}
+	if p.subject, err = parseHeader(funcs, name+".subject", fm.Subject); err != nil {
+		return nil, err
+	}
+	if p.preheader, err = parseHeader(funcs, name+".preheader", fm.Preheader); err != nil {
+		return nil, err
+	}
+	if p.fromName, err = parseHeader(funcs, name+".from_name", fm.FromName); err != nil {
+		return nil, err
+	}
+
+	set, err := base.Clone()
+	if err != nil {
+		return nil, err
+	}
+	if p.body, err = set.New(name).Parse(body); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// parseHeader parses a header template. Headers are plain text, so they are
+// parsed with text/template to avoid HTML escaping.
+func parseHeader(funcs template.FuncMap, name, src string) (*texttemplate.Template, error) {
+	return texttemplate.New(name).Funcs(texttemplate.FuncMap(funcs)).Parse(src)
+}
+
+func execute(t *texttemplate.Template, data any) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// injectPreheader places the hidden preheader right after the opening body tag.
+func injectPreheader(body, preheader string) string {
+	if preheader == "" {
+		return body
+	}
+
+	start := strings.Index(strings.ToLower(body), "<body")
+	if start < 0 {
+		return fmt.Sprintf(preheaderTag, html.EscapeString(preheader)) + body
+	}
+	end := strings.Index(body[start:], ">")
+	if end < 0 {
+		return body
+	}
+
+	at := start + end + 1
+	return body[:at] + fmt.Sprintf(preheaderTag, html.EscapeString(preheader)) + body[at:]
+}
+
+// fileSystems returns the file systems to read templates from, the embedded
+// defaults first so the templates directory overrides them.
+func (r *Registry) fileSystems() []fs.FS {
+	fsyss := []fs.FS{Defaults}
+	if r.dir == "" {
+		return fsyss
+	}
+	if _, err := os.Stat(r.dir); os.IsNotExist(err) {
+		r.logger.Debug("templates directory not found, using defaults", zap.String("dir", r.dir))
+		return fsyss
+	}
+	return append(fsyss, os.DirFS(r.dir))
+}
+
+// readSources returns the template sources keyed by name (file name without
+// the extension), later file systems overriding earlier ones by name.
+func readSources(fsyss ...fs.FS) (map[string]string, error) {
+	sources := make(map[string]string)
+	for _, fsys := range fsyss {
+		files, err := fs.Glob(fsys, "*.html")
+		if err != nil {
+			return nil, fmt.Errorf("error listing templates: %v", err)
+		}
+
+		for _, file := range files {
+			content, err := fs.ReadFile(fsys, file)
+			if err != nil {
+				return nil, fmt.Errorf("error reading template %s: %v", file, err)
+			}
+			sources[strings.TrimSuffix(file, path.Ext(file))] = string(content)
+		}
+	}
+	return sources, nil
+}
@@ -0,0 +1,62 @@
+package templates
+
+import (
+	// Go Internal Packages
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	// Local Packages
+	config "github.com/satya-ajayy/Emailer/config"
+	alert "github.com/satya-ajayy/Emailer/utils/alert"
+
+	// External Packages
+	"go.uber.org/zap"
+)
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	registry, err := NewRegistry(zap.NewNop(), config.Templates{Dir: dir, Default: "problems", DefaultLocale: "en"},
+		alert.Discard)
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+	fixture, err := registry.Fixture("problems", "default")
+	if err != nil {
+		t.Fatalf("Fixture() error = %v", err)
+	}
+	if err = registry.Validate("problems", fixture); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go registry.Watch(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	// a schema written next to the templates replaces the embedded one
+	schema := []byte(`{"type": "object", "required": ["data"]}`)
+	if err = os.WriteFile(filepath.Join(dir, "problems.schema.json"), schema, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for registry.Validate("problems", fixture) == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("schema not reloaded")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// fixtures are read on each call
+	if err = os.MkdirAll(filepath.Join(dir, "fixtures", "problems"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "fixtures", "problems", "data.json"), []byte(`{"data": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = registry.Fixture("problems", "data"); err != nil {
+		t.Errorf("Fixture() of the new fixture error = %v", err)
+	}
+}
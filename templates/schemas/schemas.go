@@ -0,0 +1,146 @@
+// Package schemas validates template data against the JSON Schemas of the
+// templates. It's kept apart from the templates so the client can validate
+// messages without the registry and its alerting.
+package schemas
+
+import (
+	// Go Internal Packages
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	// Local Packages
+	errors "github.com/satya-ajayy/Emailer/errors"
+
+	// External Packages
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Defaults holds the schemas of the templates baked into the binary.
+//
+//go:embed *.schema.json
+var Defaults embed.FS
+
+// Suffix marks the JSON Schema a template's data must satisfy, e.g.
+// problems.schema.json for problems.html.
+const Suffix = ".schema.json"
+
+// Read compiles the <name>.schema.json files keyed by template name, later
+// file systems overriding earlier ones by name.
+func Read(fsyss ...fs.FS) (Schemas, error) {
+	sources := make(map[string][]byte)
+	for _, fsys := range fsyss {
+		files, err := fs.Glob(fsys, "*"+Suffix)
+		if err != nil {
+			return nil, fmt.Errorf("error listing schemas: %v", err)
+		}
+
+		for _, file := range files {
+			content, err := fs.ReadFile(fsys, file)
+			if err != nil {
+				return nil, fmt.Errorf("error reading schema %s: %v", file, err)
+			}
+			sources[strings.TrimSuffix(file, Suffix)] = content
+		}
+	}
+
+	schemas := make(Schemas, len(sources))
+	for name, content := range sources {
+		compiler := jsonschema.NewCompiler()
+		compiler.AssertFormat = true
+
+		url := name + Suffix
+		if err := compiler.AddResource(url, bytes.NewReader(content)); err != nil {
+			return nil, fmt.Errorf("invalid schema %s: %v", url, err)
+		}
+		schema, err := compiler.Compile(url)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema %s: %v", url, err)
+		}
+		schemas[name] = schema
+	}
+	return schemas, nil
+}
+
+// Schemas are the compiled JSON Schemas of templates keyed by name, to check
+// template data outside of a registry, e.g. in the client before publishing.
+type Schemas map[string]*jsonschema.Schema
+
+// Default compiles the schemas of the embedded default templates.
+func Default() (Schemas, error) {
+	return Read(Defaults)
+}
+
+// Validate checks the raw data against the JSON Schema of the named template.
+// Templates without a schema, or unknown to the set, accept any data.
+func (s Schemas) Validate(name string, data []byte) error {
+	return Validate(s[name], data)
+}
+
+// Validate checks the raw data against the schema, when there is one.
+func Validate(schema *jsonschema.Schema, data []byte) error {
+	if schema == nil {
+		return nil
+	}
+
+	var doc any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return errors.InvalidBodyErr(err)
+	}
+	if err := schema.Validate(doc); err != nil {
+		return errors.ValidationFailedErr(validationErrors(err))
+	}
+	return nil
+}
+
+// validationErrors flattens the schema validation error tree into field
+// errors keyed by the dotted path of the offending value.
+func validationErrors(err error) error {
+	ve, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return err
+	}
+
+	builder := errors.ValidationErrs()
+	addCauses(builder, ve)
+	if builder.Len() == 0 {
+		builder.Add(fieldPath(ve.InstanceLocation), ve.Message)
+	}
+	return builder.Err()
+}
+
+func addCauses(builder *errors.ValidationErrorBuilder, ve *jsonschema.ValidationError) {
+	if len(ve.Causes) > 0 {
+		for _, cause := range ve.Causes {
+			addCauses(builder, cause)
+		}
+		return
+	}
+
+	// report each missing property on its own path
+	if missing, ok := strings.CutPrefix(ve.Message, "missing properties: "); ok {
+		for _, property := range strings.Split(missing, ", ") {
+			field := strings.Trim(property, "'")
+			if parent := fieldPath(ve.InstanceLocation); parent != "" {
+				field = parent + "." + field
+			}
+			builder.Add(field, "is required")
+		}
+		return
+	}
+
+	builder.Add(fieldPath(ve.InstanceLocation), ve.Message)
+}
+
+// fieldPath converts a JSON pointer such as /problems/0/link into the dotted
+// path problems.0.link.
+func fieldPath(pointer string) string {
+	pointer = strings.TrimPrefix(pointer, "/")
+	pointer = strings.ReplaceAll(pointer, "/", ".")
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(pointer)
+}
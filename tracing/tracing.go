@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	// Go Internal Packages
+	"context"
+	"fmt"
+
+	// Local Packages
+	config "github.com/satya-ajayy/Emailer/config"
+	models "github.com/satya-ajayy/Emailer/models"
+
+	// External Packages
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer returns the named tracer of the global provider. Spans are no-ops
+// until a provider is installed with NewProvider.
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer(name)
+}
+
+// NewExporter creates an OTLP over HTTP exporter sending spans to the
+// configured endpoint.
+func NewExporter(ctx context.Context, conf config.Tracing) (sdktrace.SpanExporter, error) {
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(conf.Endpoint)}
+	if conf.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating OTLP exporter: %v", err)
+	}
+	return exporter, nil
+}
+
+// NewProvider creates a tracer provider exporting to the given exporter
+// (e.g. tracetest.NewInMemoryExporter in tests) and installs it, along with
+// the W3C trace context propagator, as the global one.
+func NewProvider(serviceName string, exporter sdktrace.SpanExporter, sampleRatio float64) *sdktrace.TracerProvider {
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+	return provider
+}
+
+// Extract returns a context carrying the trace context found in the record
+// headers, so the spans of the record continue the producer's trace.
+func Extract(ctx context.Context, record models.Record) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, models.HeaderCarrier{Record: &record})
+}
+
+// Inject writes the trace context of ctx into the record headers.
+func Inject(ctx context.Context, record *models.Record) {
+	otel.GetTextMapPropagator().Inject(ctx, models.HeaderCarrier{Record: record})
+}
+
+// End marks the span as failed when err is not nil and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,294 @@
+package alert
+
+import (
+	// Go Internal Packages
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	// Local Packages
+	config "github.com/satya-ajayy/Emailer/config"
+	errors "github.com/satya-ajayy/Emailer/errors"
+	models "github.com/satya-ajayy/Emailer/models"
+	slack "github.com/satya-ajayy/Emailer/utils/slack"
+
+	// External Packages
+	"go.uber.org/zap"
+	"gopkg.in/gomail.v2"
+)
+
+// Event sources, used by the sinks' routing rules.
+const (
+	SourceKafka     = "kafka"
+	SourceTemplates = "templates"
+)
+
+// Severity orders the events, sinks only get the ones at or above their
+// min_severity.
+type Severity int
+
+const (
+	Info Severity = iota
+	Warning
+	Error
+	Critical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	case Critical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseSeverity parses the name of a severity, an empty name being Info.
+func ParseSeverity(name string) (Severity, error) {
+	for s := Info; s <= Critical; s++ {
+		if name == s.String() {
+			return s, nil
+		}
+	}
+	if name == "" {
+		return Info, nil
+	}
+	return Info, fmt.Errorf("unknown severity %q", name)
+}
+
+// Event is something the operators should know about. Failed is set when the
+// event is about a record that couldn't be processed.
+type Event struct {
+	Title    string
+	Message  string
+	Severity Severity
+	Source   string
+	Time     time.Time
+	Failed   *models.FailedRecord
+}
+
+// FailedRecordEvent describes a record that couldn't be processed. Invalid
+// records are warnings since sending them again can't help, other failures
+// are errors.
+func FailedRecordEvent(failed models.FailedRecord) Event {
+	severity := Error
+	if errors.IsPermanent(failed.Err) {
+		severity = Warning
+	}
+	return Event{
+		Title:    "Error In Emailer",
+		Message:  fmt.Sprintf("Failed to process %s: %v", failed.Record.Position(), failed.Err),
+		Severity: severity,
+		Source:   SourceKafka,
+		Time:     time.Now(),
+		Failed:   &failed,
+	}
+}
+
+// NoticeEvent describes an operational problem that isn't tied to a record.
+func NoticeEvent(source, title, message string, severity Severity) Event {
+	return Event{Title: title, Message: message, Severity: severity, Source: source, Time: time.Now()}
+}
+
+// Notifier sends events to a destination.
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// Discard is a Notifier dropping every event, for the command line tools.
+var Discard Notifier = discard{}
+
+type discard struct{}
+
+func (discard) Notify(context.Context, Event) error { return nil }
+
+// sink is a configured Notifier with its routing rules.
+type sink struct {
+	name        string
+	notifier    *Queue
+	minSeverity Severity
+	sources     []string
+	topics      []string
+	// skipSMTP drops the failures of the SMTP server, for the sinks sending
+	// through it
+	skipSMTP bool
+}
+
+// accepts reports whether the event passes the sink's severity filter and
+// routing rules.
+func (s sink) accepts(event Event) bool {
+	if event.Severity < s.minSeverity {
+		return false
+	}
+	var smtpErr *models.SMTPError
+	if s.skipSMTP && event.Failed != nil && errors.As(event.Failed.Err, &smtpErr) {
+		return false
+	}
+	if len(s.sources) > 0 && !slices.Contains(s.sources, event.Source) {
+		return false
+	}
+	if len(s.topics) > 0 && (event.Failed == nil || !slices.Contains(s.topics, event.Failed.Record.Topic)) {
+		return false
+	}
+	return true
+}
+
+// Router is a Notifier fanning the events out to the sinks accepting them.
+type Router struct {
+	logger *zap.Logger
+	mu     sync.RWMutex
+	sinks  []sink
+	stop   context.CancelFunc
+}
+
+// New creates the Router with the configured sinks. Every sink sends from
+// its own bounded queue until the context is canceled, and Slack alerts are
+// aggregated too. Email sinks send through the dialer, so they don't get the
+// SMTP failures of the records.
+func New(ctx context.Context, logger *zap.Logger, conf config.Alerts, slackConf config.Slack, isProd bool,
+	dialer *gomail.Dialer) (*Router, error) {
+	r := &Router{logger: logger}
+	if err := r.Apply(ctx, conf, slackConf, isProd, dialer); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Apply replaces the sinks with the configured ones. The queues of the
+// previous sinks are closed in the background and, once they've sent their
+// events, their Slack aggregators are stopped, which sends their summaries.
+func (r *Router) Apply(ctx context.Context, conf config.Alerts, slackConf config.Slack, isProd bool,
+	dialer *gomail.Dialer) error {
+	redactor := slack.NewRedactor(slackConf)
+	sinksCtx, stop := context.WithCancel(ctx)
+
+	sinks := make([]sink, 0, len(conf.Sinks))
+	for _, sc := range conf.Sinks {
+		minSeverity, err := ParseSeverity(sc.MinSeverity)
+		if err != nil {
+			stop()
+			return fmt.Errorf("alert sink %s: %v", sc.Name, err)
+		}
+
+		var notifier Notifier
+		switch sc.Type {
+		case config.SinkSlack:
+			sinkConf := slackConf
+			if sc.URL != "" {
+				sinkConf.WebhookURL = sc.URL
+			}
+			aggregator := slack.NewAggregator(slack.NewSender(sinkConf, isProd), r.logger, sinkConf.Window)
+			go aggregator.Run(sinksCtx)
+			notifier = NewSlack(aggregator)
+		case config.SinkWebhook:
+			notifier = NewWebhook(sc.URL.Value(), sc.Secret.Value(), redactor)
+		case config.SinkTeams:
+			notifier = NewTeams(sc.URL.Value(), redactor)
+		case config.SinkPagerDuty:
+			notifier = NewPagerDuty(sc.URL.Value(), sc.RoutingKey.Value(), redactor)
+		case config.SinkEmail:
+			notifier = NewEmail(dialer, sc.To, redactor)
+		default:
+			stop()
+			return fmt.Errorf("alert sink %s: unknown type %q", sc.Name, sc.Type)
+		}
+
+		sinks = append(sinks, sink{
+			name:        sc.Name,
+			notifier:    NewQueue(sinksCtx, sc.Name, notifier, queueSize, r.logger),
+			minSeverity: minSeverity,
+			sources:     sc.Sources,
+			topics:      sc.Topics,
+			skipSMTP:    sc.Type == config.SinkEmail,
+		})
+	}
+
+	r.mu.Lock()
+	previous, stopPrevious := r.sinks, r.stop
+	r.sinks, r.stop = sinks, stop
+	r.mu.Unlock()
+
+	// no Notify uses the previous queues past the swap, and their events
+	// reach the aggregators before these are stopped
+	go func() {
+		for _, s := range previous {
+			<-s.notifier.Close()
+		}
+		if stopPrevious != nil {
+			stopPrevious()
+		}
+	}()
+	return nil
+}
+
+// Notify queues the event for every sink accepting it, without waiting for
+// it to be sent. The sinks whose queue is full drop it, their errors are
+// joined.
+func (r *Router) Notify(ctx context.Context, event Event) error {
+	// held until the events are queued, Apply closes the queues it replaces
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var errs []error
+	for _, s := range r.sinks {
+		if !s.accepts(event) {
+			continue
+		}
+		if err := s.notifier.Notify(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("alert sink %s: %v", s.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// details lists the facts about the event shown by the sinks, with the
+// personal data redacted. Record failures get the same fields as the Slack
+// alerts.
+func details(event Event, redactor *slack.Redactor) [][2]string {
+	facts := [][2]string{
+		{"Severity", event.Severity.String()},
+		{"Source", event.Source},
+	}
+	if event.Failed == nil {
+		return facts
+	}
+
+	failed := event.Failed
+	userLinks, _ := failed.Record.UserLinks()
+	recipient := "unknown"
+	if userLinks.User.MailID != "" {
+		recipient = redactor.Address(userLinks.User.MailID)
+	}
+	template := "default"
+	if userLinks.Template != "" {
+		template = userLinks.Template
+	}
+	return append(facts,
+		[2]string{"Recipient", recipient},
+		[2]string{"Template", template},
+		[2]string{"Record", failed.Record.Position()},
+		[2]string{"Attempt", strconv.Itoa(failed.Attempts)},
+		[2]string{"Error Kind", errors.KindOf(failed.Err).String()},
+	)
+}
+
+// plainText formats the event and its details for the text based sinks.
+func plainText(event Event, redactor *slack.Redactor) string {
+	var b strings.Builder
+	b.WriteString(redactor.Text(event.Message))
+	b.WriteString("\n")
+	for _, fact := range details(event, redactor) {
+		fmt.Fprintf(&b, "\n%s: %s", fact[0], fact[1])
+	}
+	return b.String()
+}
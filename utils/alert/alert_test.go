@@ -0,0 +1,172 @@
+package alert
+
+import (
+	// Go Internal Packages
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	// Local Packages
+	config "github.com/satya-ajayy/Emailer/config"
+	errors "github.com/satya-ajayy/Emailer/errors"
+	models "github.com/satya-ajayy/Emailer/models"
+
+	// External Packages
+	"go.uber.org/zap"
+)
+
+func TestSinkAccepts(t *testing.T) {
+	smtpFailure := FailedRecordEvent(models.FailedRecord{
+		Record: models.Record{Topic: "emails"},
+		Err:    &models.SMTPError{Err: fmt.Errorf("421 service not available")},
+	})
+	renderFailure := FailedRecordEvent(models.FailedRecord{
+		Record: models.Record{Topic: "emails"},
+		Err:    fmt.Errorf("error rendering email: missing key"),
+	})
+	invalid := FailedRecordEvent(models.FailedRecord{
+		Record: models.Record{Topic: "emails"},
+		Err:    errors.E(errors.Invalid, "error unmarshalling JSON"),
+	})
+	notice := NoticeEvent(SourceTemplates, "Templates Not Reloaded", "parse error", Error)
+
+	tests := []struct {
+		name  string
+		sink  sink
+		event Event
+		want  bool
+	}{
+		{name: "smtp failure", sink: sink{}, event: smtpFailure, want: true},
+		{name: "smtp failure to email", sink: sink{skipSMTP: true}, event: smtpFailure, want: false},
+		{name: "other failure to email", sink: sink{skipSMTP: true}, event: renderFailure, want: true},
+		{name: "notice to email", sink: sink{skipSMTP: true}, event: notice, want: true},
+		{name: "below min severity", sink: sink{minSeverity: Error}, event: invalid, want: false},
+		{name: "other source", sink: sink{sources: []string{SourceKafka}}, event: notice, want: false},
+		{name: "other topic", sink: sink{topics: []string{"digests"}}, event: renderFailure, want: false},
+		{name: "notice with topics", sink: sink{topics: []string{"emails"}}, event: notice, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.sink.accepts(tt.event); got != tt.want {
+			t.Errorf("%s: accepts() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// blocked is a Notifier waiting for release before taking each event.
+type blocked struct {
+	release chan struct{}
+	got     chan Event
+}
+
+func (b *blocked) Notify(_ context.Context, event Event) error {
+	<-b.release
+	b.got <- event
+	return nil
+}
+
+func TestQueue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	notifier := &blocked{release: make(chan struct{}), got: make(chan Event, 4)}
+	q := NewQueue(ctx, "slow", notifier, 2, zap.NewNop())
+
+	// the first event is taken by the sender, two more fill the queue and
+	// the last one is dropped, all without waiting for the notifier
+	done := make(chan []error)
+	go func() {
+		var errs []error
+		for i := 1; i <= 4; i++ {
+			errs = append(errs, q.Notify(ctx, NoticeEvent(SourceKafka, fmt.Sprint(i), "", Info)))
+			if i == 1 {
+				// let the sender take the first event
+				for len(q.events) > 0 {
+					time.Sleep(time.Millisecond)
+				}
+			}
+		}
+		done <- errs
+	}()
+
+	var errs []error
+	select {
+	case errs = <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Notify() waited for the notifier")
+	}
+	for i, err := range errs {
+		if (err != nil) != (i == 3) {
+			t.Errorf("Notify() of event %d error = %v", i+1, err)
+		}
+	}
+
+	// the queued events are still sent once the queue is stopped
+	cancel()
+	close(notifier.release)
+	for _, want := range []string{"1", "2", "3"} {
+		select {
+		case event := <-notifier.got:
+			if event.Title != want {
+				t.Errorf("sent %q, want %q", event.Title, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %s not sent", want)
+		}
+	}
+}
+
+func TestApplyDrains(t *testing.T) {
+	release := make(chan struct{})
+	got := make(chan string, 3)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		var payload webhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		// the sender gave up on the request when its context is done
+		time.Sleep(10 * time.Millisecond)
+		if r.Context().Err() != nil {
+			payload.Title += " canceled"
+		}
+		got <- payload.Title
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	slackConf := config.Slack{Window: time.Minute}
+	router, err := New(ctx, zap.NewNop(), config.Alerts{Sinks: []config.AlertSink{
+		{Name: "hook", Type: config.SinkWebhook, URL: config.Secret(server.URL)},
+	}}, slackConf, false, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for i := 1; i <= 3; i++ {
+		if err = router.Notify(ctx, NoticeEvent(SourceKafka, fmt.Sprint(i), "", Error)); err != nil {
+			t.Fatalf("Notify() error = %v", err)
+		}
+	}
+
+	// the events queued before the reload are still sent, the one being sent
+	// included
+	if err = router.Apply(ctx, config.Alerts{}, slackConf, false, nil); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if err = router.Notify(ctx, NoticeEvent(SourceKafka, "4", "", Error)); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	close(release)
+	for _, want := range []string{"1", "2", "3"} {
+		select {
+		case title := <-got:
+			if title != want {
+				t.Errorf("sent %q, want %q", title, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %s not sent", want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package alert
+
+import (
+	// Go Internal Packages
+	"context"
+	"fmt"
+
+	// Local Packages
+	slack "github.com/satya-ajayy/Emailer/utils/slack"
+
+	// External Packages
+	"gopkg.in/gomail.v2"
+)
+
+// Email is a Notifier mailing the events to the operators through the SMTP
+// server the service sends its emails with.
+type Email struct {
+	dialer   *gomail.Dialer
+	to       []string
+	redactor *slack.Redactor
+}
+
+// NewEmail creates a new Email notifier sending to the addresses.
+func NewEmail(dialer *gomail.Dialer, to []string, redactor *slack.Redactor) *Email {
+	return &Email{dialer: dialer, to: to, redactor: redactor}
+}
+
+// Notify mails the event as plain text.
+func (e *Email) Notify(_ context.Context, event Event) error {
+	m := gomail.NewMessage()
+	m.SetHeader("From", e.dialer.Username)
+	m.SetHeader("To", e.to...)
+	m.SetHeader("Subject", fmt.Sprintf("[emailer] %s: %s", event.Severity, event.Title))
+	m.SetBody("text/plain", plainText(event, e.redactor))
+	return e.dialer.DialAndSend(m)
+}
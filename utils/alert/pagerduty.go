@@ -0,0 +1,88 @@
+package alert
+
+import (
+	// Go Internal Packages
+	"context"
+	"encoding/json"
+	"time"
+
+	// Local Packages
+	slack "github.com/satya-ajayy/Emailer/utils/slack"
+)
+
+// pagerDutyURL is the Events API v2 endpoint, used when the sink has no url.
+const pagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     time.Time         `json:"timestamp"`
+	Component     string            `json:"component"`
+	CustomDetails map[string]string `json:"custom_details"`
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	DedupKey    string           `json:"dedup_key"`
+	Payload     pagerDutyPayload `json:"payload"`
+}
+
+// PagerDuty is a Notifier triggering PagerDuty incidents through the Events
+// API v2. Events with the same title and error signature share a dedup key,
+// so repeated failures update one incident instead of opening new ones.
+type PagerDuty struct {
+	url        string
+	routingKey string
+	redactor   *slack.Redactor
+}
+
+// NewPagerDuty creates a new PagerDuty notifier for the integration's routing
+// key, posting to the default endpoint when url is empty.
+func NewPagerDuty(url, routingKey string, redactor *slack.Redactor) *PagerDuty {
+	if url == "" {
+		url = pagerDutyURL
+	}
+	return &PagerDuty{url: url, routingKey: routingKey, redactor: redactor}
+}
+
+// Notify triggers an incident for the event.
+func (p *PagerDuty) Notify(ctx context.Context, event Event) error {
+	dedupKey := "github.com/satya-ajayy/Emailer/" + event.Source + "/" + event.Title
+	if event.Failed != nil {
+		dedupKey += "/" + slack.Signature(event.Failed.Err)
+	}
+
+	customDetails := make(map[string]string)
+	for _, fact := range details(event, p.redactor) {
+		customDetails[fact[0]] = fact[1]
+	}
+
+	body, err := json.Marshal(pagerDutyEvent{
+		RoutingKey:  p.routingKey,
+		EventAction: "trigger",
+		DedupKey:    dedupKey,
+		Payload: pagerDutyPayload{
+			Summary:       truncateSummary(p.redactor.Text(event.Message)),
+			Source:        "emailer",
+			Severity:      event.Severity.String(),
+			Timestamp:     event.Time,
+			Component:     event.Source,
+			CustomDetails: customDetails,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return post(ctx, p.url, body, nil)
+}
+
+// truncateSummary keeps the summary within the 1024 characters PagerDuty
+// accepts.
+func truncateSummary(summary string) string {
+	if runes := []rune(summary); len(runes) > 1024 {
+		return string(runes[:1023]) + "…"
+	}
+	return summary
+}
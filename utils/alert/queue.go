@@ -0,0 +1,89 @@
+package alert
+
+import (
+	// Go Internal Packages
+	"context"
+	"fmt"
+
+	// External Packages
+	"go.uber.org/zap"
+)
+
+// queueSize is how many events a sink holds while it's sending, the events
+// beyond are dropped.
+const queueSize = 100
+
+// Queue is a Notifier handing the events to another one in its own goroutine,
+// so a slow or unreachable destination doesn't hold back the caller, e.g. the
+// pipeline.
+type Queue struct {
+	name     string
+	notifier Notifier
+	logger   *zap.Logger
+	events   chan Event
+	done     chan struct{}
+}
+
+// NewQueue creates a new Queue holding up to size events for the notifier,
+// it sends them until the context is canceled or the queue closed, then sends
+// the queued ones.
+func NewQueue(ctx context.Context, name string, notifier Notifier, size int, logger *zap.Logger) *Queue {
+	q := &Queue{
+		name:     name,
+		notifier: notifier,
+		logger:   logger,
+		events:   make(chan Event, size),
+		done:     make(chan struct{}),
+	}
+	go q.run(ctx)
+	return q
+}
+
+// Notify queues the event, it returns an error when the queue is full and
+// the event was dropped.
+func (q *Queue) Notify(_ context.Context, event Event) error {
+	select {
+	case q.events <- event:
+		return nil
+	default:
+		return fmt.Errorf("queue is full, dropped %q", event.Title)
+	}
+}
+
+// Close stops the queue once the queued events are sent, the returned channel
+// is closed then. Notify mustn't be called after Close.
+func (q *Queue) Close() <-chan struct{} {
+	close(q.events)
+	return q.done
+}
+
+func (q *Queue) run(ctx context.Context) {
+	defer close(q.done)
+	for {
+		select {
+		case event, ok := <-q.events:
+			if !ok {
+				return
+			}
+			q.send(ctx, event)
+		case <-ctx.Done():
+			for {
+				select {
+				case event, ok := <-q.events:
+					if !ok {
+						return
+					}
+					q.send(context.WithoutCancel(ctx), event)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (q *Queue) send(ctx context.Context, event Event) {
+	if err := q.notifier.Notify(ctx, event); err != nil {
+		q.logger.Error("failed to send alert", zap.String("sink", q.name), zap.Error(err))
+	}
+}
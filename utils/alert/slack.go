@@ -0,0 +1,28 @@
+package alert
+
+import (
+	// Go Internal Packages
+	"context"
+
+	// Local Packages
+	slack "github.com/satya-ajayy/Emailer/utils/slack"
+)
+
+// Slack is a Notifier posting to Slack through a slack.Sender, usually the
+// aggregator so failures are summarized instead of posted one by one.
+type Slack struct {
+	sender slack.Sender
+}
+
+// NewSlack creates a new Slack notifier
+func NewSlack(sender slack.Sender) *Slack {
+	return &Slack{sender: sender}
+}
+
+// Notify posts the event, record failures with their record context.
+func (s *Slack) Notify(_ context.Context, event Event) error {
+	if event.Failed != nil {
+		return s.sender.SendAlert(*event.Failed)
+	}
+	return s.sender.SendNotice(event.Title, event.Message)
+}
@@ -0,0 +1,70 @@
+package alert
+
+import (
+	// Go Internal Packages
+	"context"
+	"encoding/json"
+
+	// Local Packages
+	slack "github.com/satya-ajayy/Emailer/utils/slack"
+)
+
+// teamsColors are the card colors of the severities.
+var teamsColors = map[Severity]string{
+	Info:     "2EB886",
+	Warning:  "DAA038",
+	Error:    "D00000",
+	Critical: "7B0000",
+}
+
+type teamsFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type teamsSection struct {
+	Text  string      `json:"text"`
+	Facts []teamsFact `json:"facts"`
+}
+
+// teamsCard is a connector MessageCard, accepted by Teams incoming webhooks.
+type teamsCard struct {
+	Type       string         `json:"@type"`
+	Context    string         `json:"@context"`
+	Summary    string         `json:"summary"`
+	ThemeColor string         `json:"themeColor"`
+	Title      string         `json:"title"`
+	Sections   []teamsSection `json:"sections"`
+}
+
+// Teams is a Notifier posting the events to a Microsoft Teams channel.
+type Teams struct {
+	url      string
+	redactor *slack.Redactor
+}
+
+// NewTeams creates a new Teams notifier posting to the incoming webhook URL.
+func NewTeams(url string, redactor *slack.Redactor) *Teams {
+	return &Teams{url: url, redactor: redactor}
+}
+
+// Notify posts the event as a card.
+func (t *Teams) Notify(ctx context.Context, event Event) error {
+	section := teamsSection{Text: "<pre>" + t.redactor.Text(event.Message) + "</pre>"}
+	for _, fact := range details(event, t.redactor) {
+		section.Facts = append(section.Facts, teamsFact{Name: fact[0], Value: fact[1]})
+	}
+
+	body, err := json.Marshal(teamsCard{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		Summary:    event.Title,
+		ThemeColor: teamsColors[event.Severity],
+		Title:      event.Title,
+		Sections:   []teamsSection{section},
+	})
+	if err != nil {
+		return err
+	}
+	return post(ctx, t.url, body, nil)
+}
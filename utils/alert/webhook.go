@@ -0,0 +1,81 @@
+package alert
+
+import (
+	// Go Internal Packages
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	// Local Packages
+	slack "github.com/satya-ajayy/Emailer/utils/slack"
+)
+
+// Webhook signature headers. The signature is the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the sink secret, so receivers can reject
+// forged and replayed requests.
+const (
+	HeaderTimestamp = "X-Emailer-Timestamp"
+	HeaderSignature = "X-Emailer-Signature"
+)
+
+// webhookPayload is the JSON body posted by the Webhook sink.
+type webhookPayload struct {
+	Title    string            `json:"title"`
+	Message  string            `json:"message"`
+	Severity string            `json:"severity"`
+	Source   string            `json:"source"`
+	Time     time.Time         `json:"time"`
+	Details  map[string]string `json:"details"`
+}
+
+// Webhook is a Notifier posting the events as JSON to any HTTP endpoint.
+type Webhook struct {
+	url      string
+	secret   string
+	redactor *slack.Redactor
+}
+
+// NewWebhook creates a new Webhook notifier, the requests are only signed
+// when the secret is set.
+func NewWebhook(url, secret string, redactor *slack.Redactor) *Webhook {
+	return &Webhook{url: url, secret: secret, redactor: redactor}
+}
+
+// Notify posts the event.
+func (w *Webhook) Notify(ctx context.Context, event Event) error {
+	payload := webhookPayload{
+		Title:    event.Title,
+		Message:  w.redactor.Text(event.Message),
+		Severity: event.Severity.String(),
+		Source:   event.Source,
+		Time:     event.Time,
+		Details:  make(map[string]string),
+	}
+	for _, fact := range details(event, w.redactor) {
+		payload.Details[fact[0]] = fact[1]
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	headers := make(map[string]string)
+	if w.secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		headers[HeaderTimestamp] = timestamp
+		headers[HeaderSignature] = "sha256=" + Sign(w.secret, timestamp, body)
+	}
+	return post(ctx, w.url, body, headers)
+}
+
+// Sign returns the signature of the webhook body sent at the timestamp.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
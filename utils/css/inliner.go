@@ -0,0 +1,161 @@
+package css
+
+import (
+	// Go Internal Packages
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	// External Packages
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// inlineSpecificity ranks declarations from an existing style attribute above
+// any selector, as browsers do.
+var inlineSpecificity = specificity{1 << 16, 0, 0}
+
+// matched is a declaration that applies to an element.
+type matched struct {
+	declaration
+	specificity specificity
+	order       int
+}
+
+// Inline moves the rules of the <style> blocks of an HTML document into the
+// style attributes of the elements they match, since Gmail and Outlook strip
+// or ignore most of the <style> blocks. Rules that can't be inlined (media
+// queries, pseudo classes, ...) are kept in a <style> block in the head.
+func Inline(document string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(document))
+	if err != nil {
+		return "", fmt.Errorf("error parsing HTML: %v", err)
+	}
+
+	var styles []*html.Node
+	walk(doc, func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Style {
+			styles = append(styles, n)
+		}
+	})
+	if len(styles) == 0 {
+		return document, nil
+	}
+
+	var rules []rule
+	var leftover strings.Builder
+	order := 0
+	for _, style := range styles {
+		var sheet strings.Builder
+		for c := style.FirstChild; c != nil; c = c.NextSibling {
+			sheet.WriteString(c.Data)
+		}
+
+		var parsed []rule
+		var rest string
+		parsed, rest, order = parseStylesheet(sheet.String(), order)
+		rules = append(rules, parsed...)
+		leftover.WriteString(rest)
+		style.Parent.RemoveChild(style)
+	}
+
+	walk(doc, func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			applyRules(n, rules)
+		}
+	})
+
+	if leftover.Len() > 0 {
+		if head := find(doc, atom.Head); head != nil {
+			style := &html.Node{Type: html.ElementNode, Data: "style", DataAtom: atom.Style}
+			style.AppendChild(&html.Node{Type: html.TextNode, Data: "\n" + leftover.String()})
+			head.AppendChild(style)
+		}
+	}
+
+	var out bytes.Buffer
+	if err = html.Render(&out, doc); err != nil {
+		return "", fmt.Errorf("error rendering HTML: %v", err)
+	}
+	return out.String(), nil
+}
+
+// applyRules merges the declarations of the matching rules with the existing
+// style attribute of the element, following the cascade: !important first,
+// then specificity, then source order.
+func applyRules(n *html.Node, rules []rule) {
+	var decls []matched
+	for _, r := range rules {
+		if !r.selector.matches(n) {
+			continue
+		}
+		for _, d := range r.declarations {
+			decls = append(decls, matched{declaration: d, specificity: r.selector.specificity, order: r.order})
+		}
+	}
+	if len(decls) == 0 {
+		return
+	}
+
+	existing, hasStyle := lookupAttr(n, "style")
+	for _, d := range parseDeclarations(existing) {
+		decls = append(decls, matched{declaration: d, specificity: inlineSpecificity, order: len(rules)})
+	}
+
+	sort.SliceStable(decls, func(i, j int) bool {
+		a, b := decls[i], decls[j]
+		if a.important != b.important {
+			return !a.important
+		}
+		if a.specificity != b.specificity {
+			return a.specificity.less(b.specificity)
+		}
+		return a.order < b.order
+	})
+
+	// later declarations win, but the property keeps its first position
+	var properties []string
+	winners := make(map[string]declaration)
+	for _, d := range decls {
+		if _, ok := winners[d.property]; !ok {
+			properties = append(properties, d.property)
+		}
+		winners[d.property] = d.declaration
+	}
+
+	final := make([]declaration, len(properties))
+	for i, property := range properties {
+		final[i] = winners[property]
+	}
+
+	style := formatDeclarations(final)
+	if !hasStyle {
+		n.Attr = append(n.Attr, html.Attribute{Key: "style", Val: style})
+		return
+	}
+	for i := range n.Attr {
+		if n.Attr[i].Key == "style" {
+			n.Attr[i].Val = style
+		}
+	}
+}
+
+func walk(n *html.Node, fn func(*html.Node)) {
+	fn(n)
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, fn)
+	}
+}
+
+func find(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := find(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package css
+
+import (
+	// Go Internal Packages
+	"strings"
+	"testing"
+)
+
+func TestInline(t *testing.T) {
+	tests := []struct {
+		name  string
+		style string
+		body  string
+		want  string
+	}{
+		{
+			name:  "specificity before source order",
+			style: `#intro { color: red } p.lead { color: green } p { color: blue }`,
+			body:  `<p id="intro" class="lead">Hi</p>`,
+			want:  `<p id="intro" class="lead" style="color: red">Hi</p>`,
+		},
+		{
+			name:  "later rules of the same specificity win",
+			style: `p { color: blue; margin: 0 } p { color: green }`,
+			body:  `<p>Hi</p>`,
+			want:  `<p style="color: green; margin: 0">Hi</p>`,
+		},
+		{
+			name:  "important wins over specificity",
+			style: `p { color: blue !important } #intro { color: red }`,
+			body:  `<p id="intro">Hi</p>`,
+			want:  `<p id="intro" style="color: blue !important">Hi</p>`,
+		},
+		{
+			name:  "existing inline styles kept over the rules",
+			style: `p { color: blue; margin: 0 }`,
+			body:  `<p style="color: red">Hi</p>`,
+			want:  `<p style="color: red; margin: 0">Hi</p>`,
+		},
+		{
+			name:  "important rules override inline styles",
+			style: `p { color: blue !important }`,
+			body:  `<p style="color: red">Hi</p>`,
+			want:  `<p style="color: blue !important">Hi</p>`,
+		},
+		{
+			name:  "descendant combinator",
+			style: `div span { color: red }`,
+			body:  `<div><p><span>a</span></p></div><span>b</span>`,
+			want:  `<div><p><span style="color: red">a</span></p></div><span>b</span>`,
+		},
+		{
+			name:  "child combinator",
+			style: `div > span { color: red }`,
+			body:  `<div><span>a</span><p><span>b</span></p></div>`,
+			want:  `<div><span style="color: red">a</span><p><span>b</span></p></div>`,
+		},
+		{
+			name:  "braces in strings",
+			style: `p::before { content: "}" } p { color: red }`,
+			body:  `<p>Hi</p>`,
+			want:  `<p style="color: red">Hi</p>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Inline(`<html><head><style>` + tt.style + `</style></head><body>` + tt.body + `</body></html>`)
+			if err != nil {
+				t.Fatalf("Inline() error = %v", err)
+			}
+			if !strings.Contains(got, "<body>"+tt.want+"</body>") {
+				t.Errorf("Inline() = %s, want body %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeftover(t *testing.T) {
+	tests := []struct {
+		name     string
+		sheet    string
+		rules    int
+		leftover string
+	}{
+		{
+			name:     "media query",
+			sheet:    `p { color: red } @media (max-width: 600px) { p { color: blue } }`,
+			rules:    1,
+			leftover: "@media (max-width: 600px) { p { color: blue } }\n",
+		},
+		{
+			name:     "pseudo class",
+			sheet:    `a:hover, a { color: red }`,
+			rules:    1,
+			leftover: "a:hover { color: red }\n",
+		},
+		{
+			name:     "braces in strings",
+			sheet:    `p::before{content:"}"} a[title='{'] , p{color:red}`,
+			rules:    2,
+			leftover: "p::before {content:\"}\"}\n",
+		},
+		{
+			name:     "escaped quotes",
+			sheet:    `p::after { content: "\"}" } p { color: red }`,
+			rules:    1,
+			leftover: "p::after { content: \"\\\"}\" }\n",
+		},
+		{
+			name:     "comments",
+			sheet:    `/* p { color: blue } */ p { color: red }`,
+			rules:    1,
+			leftover: "",
+		},
+		{
+			name:     "unclosed block",
+			sheet:    `p { color: red } div { color: blue`,
+			rules:    1,
+			leftover: "div { color: blue\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, leftover, _ := parseStylesheet(tt.sheet, 0)
+			if len(rules) != tt.rules {
+				t.Errorf("parsed %d rules, want %d", len(rules), tt.rules)
+			}
+			if leftover != tt.leftover {
+				t.Errorf("leftover = %q, want %q", leftover, tt.leftover)
+			}
+		})
+	}
+}
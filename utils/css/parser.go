@@ -0,0 +1,183 @@
+package css
+
+import (
+	// Go Internal Packages
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var commentRegex = regexp.MustCompile(`(?s)/\*.*?\*/`)
+
+// rule is a style rule with a single selector.
+type rule struct {
+	selector     *selector
+	declarations []declaration
+	order        int
+}
+
+type declaration struct {
+	property  string
+	value     string
+	important bool
+}
+
+// parseStylesheet splits a stylesheet into the rules that can be inlined and
+// the leftover CSS (at-rules such as media queries and selectors with pseudo
+// classes) that has to stay in the head.
+func parseStylesheet(sheet string, order int) ([]rule, string, int) {
+	sheet = commentRegex.ReplaceAllString(sheet, "")
+
+	var rules []rule
+	var leftover strings.Builder
+	for len(strings.TrimSpace(sheet)) > 0 {
+		open := openingBrace(sheet)
+		if open < 0 {
+			break
+		}
+		close := matchingBrace(sheet, open)
+		if close < 0 {
+			leftover.WriteString(strings.TrimSpace(sheet) + "\n")
+			break
+		}
+
+		prelude := strings.TrimSpace(sheet[:open])
+		block := sheet[open+1 : close]
+		sheet = sheet[close+1:]
+
+		// at-rules (@media, @font-face, ...) are kept as they are
+		if strings.HasPrefix(prelude, "@") {
+			leftover.WriteString(prelude + " {" + block + "}\n")
+			continue
+		}
+
+		decls := parseDeclarations(block)
+		var kept []string
+		for _, raw := range strings.Split(prelude, ",") {
+			raw = strings.TrimSpace(raw)
+			sel, err := parseSelector(raw)
+			if err != nil {
+				kept = append(kept, raw)
+				continue
+			}
+			rules = append(rules, rule{selector: sel, declarations: decls, order: order})
+			order++
+		}
+		if len(kept) > 0 {
+			leftover.WriteString(strings.Join(kept, ", ") + " {" + block + "}\n")
+		}
+	}
+
+	return rules, leftover.String(), order
+}
+
+// parseDeclarations parses the "property: value" pairs of a declaration block.
+func parseDeclarations(block string) []declaration {
+	var decls []declaration
+	for _, raw := range splitOutside(block, ';') {
+		property, value, ok := strings.Cut(raw, ":")
+		if !ok {
+			continue
+		}
+
+		property = strings.ToLower(strings.TrimSpace(property))
+		value = strings.TrimSpace(value)
+		if property == "" || value == "" {
+			continue
+		}
+
+		important := false
+		if idx := strings.Index(strings.ToLower(value), "!important"); idx >= 0 {
+			important = true
+			value = strings.TrimSpace(value[:idx])
+		}
+		decls = append(decls, declaration{property: property, value: value, important: important})
+	}
+	return decls
+}
+
+// openingBrace returns the index of the first brace outside quoted strings,
+// e.g. not the one of a[title="{"].
+func openingBrace(s string) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '{':
+			return i
+		}
+	}
+	return -1
+}
+
+// matchingBrace returns the index of the brace closing the one at open,
+// ignoring braces inside quoted strings such as content: "}".
+func matchingBrace(s string, open int) int {
+	var quote byte
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitOutside splits s on sep, ignoring separators inside quotes and parentheses.
+func splitOutside(s string, sep byte) []string {
+	var parts []string
+	var quote byte
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// formatDeclarations renders declarations as the value of a style attribute.
+func formatDeclarations(decls []declaration) string {
+	parts := make([]string, len(decls))
+	for i, d := range decls {
+		if d.important {
+			parts[i] = fmt.Sprintf("%s: %s !important", d.property, d.value)
+			continue
+		}
+		parts[i] = fmt.Sprintf("%s: %s", d.property, d.value)
+	}
+	return strings.Join(parts, "; ")
+}
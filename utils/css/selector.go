@@ -0,0 +1,216 @@
+package css
+
+import (
+	// Go Internal Packages
+	"fmt"
+	"strings"
+
+	// External Packages
+	"golang.org/x/net/html"
+)
+
+// specificity is the (ids, classes, types) weight of a selector.
+type specificity [3]int
+
+func (s specificity) less(o specificity) bool {
+	for i := range s {
+		if s[i] != o[i] {
+			return s[i] < o[i]
+		}
+	}
+	return false
+}
+
+// compound is a sequence of simple selectors matching a single element,
+// e.g. td.link#first[href].
+type compound struct {
+	tag     string
+	id      string
+	classes []string
+	attrs   []attrSelector
+}
+
+type attrSelector struct {
+	name  string
+	value string
+	exact bool
+}
+
+// selector is a chain of compounds joined by descendant (' ') or child ('>')
+// combinators. combinators[i] sits between parts[i] and parts[i+1].
+type selector struct {
+	parts       []compound
+	combinators []byte
+	specificity specificity
+}
+
+// parseSelector parses the selectors that can be inlined. Selectors that
+// depend on the element state (pseudo classes and elements) or on siblings
+// are rejected, so they are left in the stylesheet.
+func parseSelector(raw string) (*selector, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("empty selector")
+	}
+	if strings.ContainsAny(raw, ":+~") {
+		return nil, fmt.Errorf("unsupported selector %q", raw)
+	}
+
+	sel := &selector{}
+	tokens := strings.Fields(strings.ReplaceAll(raw, ">", " > "))
+	combinator := byte(0)
+	for _, token := range tokens {
+		if token == ">" {
+			if combinator != 0 || len(sel.parts) == 0 {
+				return nil, fmt.Errorf("invalid selector %q", raw)
+			}
+			combinator = '>'
+			continue
+		}
+
+		c, err := parseCompound(token)
+		if err != nil {
+			return nil, err
+		}
+		if len(sel.parts) > 0 {
+			if combinator == 0 {
+				combinator = ' '
+			}
+			sel.combinators = append(sel.combinators, combinator)
+		}
+		combinator = 0
+
+		sel.parts = append(sel.parts, c)
+		if c.id != "" {
+			sel.specificity[0]++
+		}
+		sel.specificity[1] += len(c.classes) + len(c.attrs)
+		if c.tag != "" && c.tag != "*" {
+			sel.specificity[2]++
+		}
+	}
+
+	if combinator != 0 {
+		return nil, fmt.Errorf("invalid selector %q", raw)
+	}
+	return sel, nil
+}
+
+func parseCompound(token string) (compound, error) {
+	var c compound
+
+	i := 0
+	for i < len(token) && token[i] != '.' && token[i] != '#' && token[i] != '[' {
+		i++
+	}
+	c.tag = strings.ToLower(token[:i])
+
+	for i < len(token) {
+		switch token[i] {
+		case '.', '#':
+			j := i + 1
+			for j < len(token) && token[j] != '.' && token[j] != '#' && token[j] != '[' {
+				j++
+			}
+			if j == i+1 {
+				return c, fmt.Errorf("invalid selector %q", token)
+			}
+			if token[i] == '.' {
+				c.classes = append(c.classes, token[i+1:j])
+			} else {
+				c.id = token[i+1 : j]
+			}
+			i = j
+		case '[':
+			j := strings.IndexByte(token[i:], ']')
+			if j < 0 {
+				return c, fmt.Errorf("invalid selector %q", token)
+			}
+			name, value, exact := strings.Cut(token[i+1:i+j], "=")
+			if exact && strings.ContainsAny(name, "~|^$*") {
+				return c, fmt.Errorf("unsupported attribute selector %q", token)
+			}
+			c.attrs = append(c.attrs, attrSelector{
+				name:  strings.ToLower(strings.TrimSpace(name)),
+				value: strings.Trim(strings.TrimSpace(value), `"'`),
+				exact: exact,
+			})
+			i += j + 1
+		default:
+			return c, fmt.Errorf("invalid selector %q", token)
+		}
+	}
+
+	return c, nil
+}
+
+// matches reports whether the element matches the selector.
+func (s *selector) matches(n *html.Node) bool {
+	return s.matchFrom(n, len(s.parts)-1)
+}
+
+func (s *selector) matchFrom(n *html.Node, idx int) bool {
+	if !s.parts[idx].matches(n) {
+		return false
+	}
+	if idx == 0 {
+		return true
+	}
+
+	for parent := n.Parent; parent != nil && parent.Type == html.ElementNode; parent = parent.Parent {
+		if s.matchFrom(parent, idx-1) {
+			return true
+		}
+		if s.combinators[idx-1] == '>' {
+			return false
+		}
+	}
+	return false
+}
+
+func (c compound) matches(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	if c.tag != "" && c.tag != "*" && c.tag != n.Data {
+		return false
+	}
+	if c.id != "" && attr(n, "id") != c.id {
+		return false
+	}
+
+	classes := strings.Fields(attr(n, "class"))
+	for _, want := range c.classes {
+		found := false
+		for _, class := range classes {
+			if class == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for _, a := range c.attrs {
+		value, ok := lookupAttr(n, a.name)
+		if !ok || (a.exact && value != a.value) {
+			return false
+		}
+	}
+	return true
+}
+
+func attr(n *html.Node, key string) string {
+	value, _ := lookupAttr(n, key)
+	return value
+}
+
+func lookupAttr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
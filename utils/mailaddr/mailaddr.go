@@ -0,0 +1,164 @@
+package mailaddr
+
+import (
+	// Go Internal Packages
+	"context"
+	"net"
+	"net/mail"
+	"strings"
+
+	// Local Packages
+	errors "github.com/satya-ajayy/Emailer/errors"
+	models "github.com/satya-ajayy/Emailer/models"
+
+	// External Packages
+	"golang.org/x/net/idna"
+)
+
+// disposableDomains are well known throwaway mailbox providers, extended by
+// the addresses.disposable_domains config.
+var disposableDomains = []string{
+	"10minutemail.com",
+	"discard.email",
+	"dispostable.com",
+	"getnada.com",
+	"guerrillamail.com",
+	"mailinator.com",
+	"maildrop.cc",
+	"sharklasers.com",
+	"temp-mail.org",
+	"throwawaymail.com",
+	"trashmail.com",
+	"yopmail.com",
+}
+
+// roleAccounts are local parts of shared or unattended mailboxes, extended by
+// the addresses.role_accounts config.
+var roleAccounts = []string{
+	"abuse",
+	"do-not-reply",
+	"donotreply",
+	"hostmaster",
+	"mailer-daemon",
+	"no-reply",
+	"noreply",
+	"postmaster",
+	"root",
+	"webmaster",
+}
+
+// Resolver looks up the mail servers of a domain. *net.Resolver satisfies it.
+type Resolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+}
+
+// StaticResolver is a Resolver answering from a fixed map of domain to mail
+// servers, meant for tests and offline runs. Unknown domains have no records.
+type StaticResolver map[string][]*net.MX
+
+// LookupMX returns the mail servers registered for the domain.
+func (s StaticResolver) LookupMX(_ context.Context, name string) ([]*net.MX, error) {
+	if records, ok := s[strings.TrimSuffix(name, ".")]; ok {
+		return records, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+// Validator parses, normalizes and checks recipient addresses against the
+// configured policies.
+type Validator struct {
+	conf       models.Addresses
+	resolver   Resolver
+	disposable map[string]bool
+	roles      map[string]bool
+}
+
+// NewValidator creates a new address Validator. The resolver is only used
+// when MX lookups are enabled.
+func NewValidator(conf models.Addresses, resolver Resolver) *Validator {
+	v := &Validator{
+		conf:       conf,
+		resolver:   resolver,
+		disposable: make(map[string]bool),
+		roles:      make(map[string]bool),
+	}
+	for _, domain := range append(disposableDomains, conf.DisposableDomains...) {
+		v.disposable[strings.ToLower(domain)] = true
+	}
+	for _, role := range append(roleAccounts, conf.RoleAccounts...) {
+		v.roles[strings.ToLower(role)] = true
+	}
+	return v
+}
+
+// Normalize parses an RFC 5322 address (with an optional display name),
+// converts its domain to lowercase punycode and applies the policies. Problems
+// are reported as validation errors on the given field.
+func (v *Validator) Normalize(ctx context.Context, field, raw string) (*mail.Address, error) {
+	ve := errors.ValidationErrs()
+
+	if strings.TrimSpace(raw) == "" {
+		return nil, errors.EmptyParamErr(field)
+	}
+
+	addr, err := mail.ParseAddress(raw)
+	if err != nil {
+		ve.Add(field, "is not a valid email address")
+		return nil, errors.ValidationFailedErr(ve.Err())
+	}
+
+	at := strings.LastIndex(addr.Address, "@")
+	local, domain := addr.Address[:at], addr.Address[at+1:]
+	domain, err = idna.Lookup.ToASCII(strings.ToLower(domain))
+	if err != nil || !strings.Contains(domain, ".") {
+		ve.Add(field, "has an invalid domain")
+		return nil, errors.ValidationFailedErr(ve.Err())
+	}
+	addr.Address = local + "@" + domain
+
+	if !v.conf.AllowDisposable && v.isDisposable(domain) {
+		ve.Add(field, "uses a disposable email domain")
+	}
+	if !v.conf.AllowRoleAccounts && v.isRole(local) {
+		ve.Add(field, "is a role account")
+	}
+	if ve.Len() > 0 {
+		return nil, errors.ValidationFailedErr(ve.Err())
+	}
+
+	if v.conf.CheckMX {
+		records, err := v.resolver.LookupMX(ctx, domain)
+		var dnsErr *net.DNSError
+		if (errors.As(err, &dnsErr) && dnsErr.IsNotFound) || (err == nil && len(records) == 0) {
+			ve.Add(field, "domain has no mail server")
+			return nil, errors.ValidationFailedErr(ve.Err())
+		}
+		if err != nil {
+			// the lookup itself failed, which is worth retrying
+			return nil, errors.E(errors.Internal, "mx lookup failed", err)
+		}
+	}
+
+	return addr, nil
+}
+
+// isDisposable reports whether the domain or any of its parents is disposable.
+func (v *Validator) isDisposable(domain string) bool {
+	for domain != "" {
+		if v.disposable[domain] {
+			return true
+		}
+		_, parent, ok := strings.Cut(domain, ".")
+		if !ok {
+			break
+		}
+		domain = parent
+	}
+	return false
+}
+
+// isRole reports whether the local part, ignoring any +tag, is a role account.
+func (v *Validator) isRole(local string) bool {
+	local, _, _ = strings.Cut(strings.ToLower(local), "+")
+	return v.roles[local]
+}
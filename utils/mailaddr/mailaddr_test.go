@@ -0,0 +1,153 @@
+package mailaddr
+
+import (
+	// Go Internal Packages
+	"context"
+	"net"
+	"testing"
+
+	// Local Packages
+	errors "github.com/satya-ajayy/Emailer/errors"
+	models "github.com/satya-ajayy/Emailer/models"
+)
+
+// fieldErrors returns the messages of the validation errors of err.
+func fieldErrors(t *testing.T, err error) []string {
+	t.Helper()
+	var ve errors.ValidationErrors
+	if !errors.As(err, &ve) {
+		t.Fatalf("error %v has no field errors", err)
+	}
+	messages := make([]string, len(ve))
+	for i, fe := range ve {
+		if fe.Field != "user.mail_id" {
+			t.Errorf("field = %q, want user.mail_id", fe.Field)
+		}
+		messages[i] = fe.Error
+	}
+	return messages
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		conf models.Addresses
+		raw  string
+		want string
+		errs []string
+	}{
+		{name: "bare address", raw: "ada@example.com", want: "<ada@example.com>"},
+		{name: "display name", raw: "Ada Lovelace <ada@example.com>", want: `"Ada Lovelace" <ada@example.com>`},
+		{name: "quoted display name", raw: `"Lovelace, Ada" <ada@example.com>`, want: `"Lovelace, Ada" <ada@example.com>`},
+		{name: "domain lowercased, local part kept", raw: "Ada@Example.COM", want: "<Ada@example.com>"},
+		{name: "IDN to punycode", raw: "josé@bücher.example", want: "<josé@xn--bcher-kva.example>"},
+		{name: "uppercase IDN", raw: "ada@BÜCHER.example", want: "<ada@xn--bcher-kva.example>"},
+		{name: "not an address", raw: "ada at example.com", errs: []string{"is not a valid email address"}},
+		{name: "domain without dot", raw: "ada@localhost", errs: []string{"has an invalid domain"}},
+		{name: "disposable", raw: "ada@mailinator.com", errs: []string{"uses a disposable email domain"}},
+		{name: "disposable subdomain", raw: "ada@eu.mailinator.com", errs: []string{"uses a disposable email domain"}},
+		{name: "disposable allowed", conf: models.Addresses{AllowDisposable: true}, raw: "ada@mailinator.com",
+			want: "<ada@mailinator.com>"},
+		{name: "configured disposable", conf: models.Addresses{DisposableDomains: []string{"Burner.io"}},
+			raw: "ada@burner.io", errs: []string{"uses a disposable email domain"}},
+		{name: "role account", raw: "noreply@example.com", errs: []string{"is a role account"}},
+		{name: "role account with tag", raw: "Postmaster+alerts@example.com", errs: []string{"is a role account"}},
+		{name: "role account allowed", conf: models.Addresses{AllowRoleAccounts: true}, raw: "noreply@example.com",
+			want: "<noreply@example.com>"},
+		{name: "configured role account", conf: models.Addresses{RoleAccounts: []string{"billing"}},
+			raw: "billing@example.com", errs: []string{"is a role account"}},
+		{name: "both policies", raw: "noreply@yopmail.com",
+			errs: []string{"uses a disposable email domain", "is a role account"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, err := NewValidator(tt.conf, nil).Normalize(context.Background(), "user.mail_id", tt.raw)
+			if tt.errs == nil {
+				if err != nil {
+					t.Fatalf("Normalize(%q) error = %v", tt.raw, err)
+				}
+				if got := addr.String(); got != tt.want {
+					t.Errorf("Normalize(%q) = %s, want %s", tt.raw, got, tt.want)
+				}
+				return
+			}
+
+			if !errors.IsPermanent(err) {
+				t.Fatalf("Normalize(%q) error = %v, want a permanent error", tt.raw, err)
+			}
+			got := fieldErrors(t, err)
+			if len(got) != len(tt.errs) {
+				t.Fatalf("Normalize(%q) errors = %v, want %v", tt.raw, got, tt.errs)
+			}
+			for i := range got {
+				if got[i] != tt.errs[i] {
+					t.Errorf("Normalize(%q) errors = %v, want %v", tt.raw, got, tt.errs)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeEmpty(t *testing.T) {
+	_, err := NewValidator(models.Addresses{}, nil).Normalize(context.Background(), "user.mail_id", "  ")
+	if errors.KindOf(err) != errors.Invalid {
+		t.Errorf("Normalize() error = %v, want an invalid input error", err)
+	}
+}
+
+// failingResolver fails every lookup, as a DNS server timing out would.
+type failingResolver struct{}
+
+func (failingResolver) LookupMX(_ context.Context, name string) ([]*net.MX, error) {
+	return nil, &net.DNSError{Err: "i/o timeout", Name: name, IsTimeout: true}
+}
+
+func TestNormalizeMX(t *testing.T) {
+	resolver := StaticResolver{
+		"example.com":           {{Host: "mx.example.com.", Pref: 10}},
+		"xn--bcher-kva.example": {{Host: "mx.xn--bcher-kva.example.", Pref: 10}},
+		"nullmx.example":        {},
+	}
+
+	tests := []struct {
+		name     string
+		resolver Resolver
+		raw      string
+		kind     errors.Kind
+	}{
+		{name: "with mail server", resolver: resolver, raw: "ada@example.com"},
+		{name: "IDN looked up as punycode", resolver: resolver, raw: "ada@bücher.example"},
+		{name: "unknown domain", resolver: resolver, raw: "ada@nowhere.example", kind: errors.Invalid},
+		{name: "no mail server", resolver: resolver, raw: "ada@nullmx.example", kind: errors.Invalid},
+		{name: "lookup failure is retried", resolver: failingResolver{}, raw: "ada@example.com", kind: errors.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewValidator(models.Addresses{CheckMX: true}, tt.resolver)
+			_, err := v.Normalize(context.Background(), "user.mail_id", tt.raw)
+			if tt.kind == errors.Other {
+				if err != nil {
+					t.Fatalf("Normalize(%q) error = %v", tt.raw, err)
+				}
+				return
+			}
+			if got := errors.KindOf(err); got != tt.kind {
+				t.Fatalf("Normalize(%q) error = %v of kind %s, want %s", tt.raw, err, got, tt.kind)
+			}
+			if tt.kind == errors.Invalid {
+				if got := fieldErrors(t, err); len(got) != 1 || got[0] != "domain has no mail server" {
+					t.Errorf("Normalize(%q) errors = %v", tt.raw, got)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeSkipsMXByDefault(t *testing.T) {
+	// a nil resolver would panic if it were used
+	if _, err := NewValidator(models.Addresses{}, nil).Normalize(context.Background(), "to", "ada@nowhere.example"); err != nil {
+		t.Errorf("Normalize() error = %v", err)
+	}
+}
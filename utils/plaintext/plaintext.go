@@ -0,0 +1,105 @@
+package plaintext
+
+import (
+	// Go Internal Packages
+	"fmt"
+	"regexp"
+	"strings"
+
+	// External Packages
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	spacesRegex   = regexp.MustCompile(`[ \t\r\f\v]+`)
+	newlinesRegex = regexp.MustCompile(`\n\s*\n\s*\n+`)
+)
+
+// FromHTML converts an HTML email body into its plain text alternative.
+// Block elements become line breaks, links keep their target in parentheses and
+// hidden elements (such as the preheader) are dropped.
+func FromHTML(document string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(document))
+	if err != nil {
+		return "", fmt.Errorf("error parsing HTML: %v", err)
+	}
+
+	var b strings.Builder
+	write(&b, doc)
+
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(spacesRegex.ReplaceAllString(line, " "))
+	}
+	text := newlinesRegex.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(text) + "\n", nil
+}
+
+func write(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		b.WriteString(strings.ReplaceAll(n.Data, "\n", " "))
+		return
+	case html.ElementNode:
+		switch n.DataAtom {
+		case atom.Head, atom.Style, atom.Script, atom.Title:
+			return
+		case atom.Br:
+			b.WriteString("\n")
+			return
+		case atom.Img:
+			if alt := attr(n, "alt"); alt != "" {
+				b.WriteString("[" + alt + "]")
+			}
+			return
+		}
+		if strings.Contains(strings.ReplaceAll(attr(n, "style"), " ", ""), "display:none") {
+			return
+		}
+	}
+
+	if isBlock(n) {
+		b.WriteString("\n")
+	}
+	if n.DataAtom == atom.Li {
+		b.WriteString("- ")
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		write(b, c)
+		if c.DataAtom == atom.Td || c.DataAtom == atom.Th {
+			b.WriteString(" ")
+		}
+	}
+
+	if n.DataAtom == atom.A {
+		if href := attr(n, "href"); href != "" && !strings.HasPrefix(href, "#") {
+			b.WriteString(" (" + href + ")")
+		}
+	}
+	if isBlock(n) {
+		b.WriteString("\n")
+	}
+}
+
+func isBlock(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	switch n.DataAtom {
+	case atom.P, atom.Div, atom.Table, atom.Tr, atom.Ul, atom.Ol, atom.Li,
+		atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Blockquote, atom.Hr:
+		return true
+	}
+	return false
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
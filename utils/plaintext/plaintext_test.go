@@ -0,0 +1,72 @@
+package plaintext
+
+import (
+	// Go Internal Packages
+	"testing"
+)
+
+func TestFromHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		want     string
+	}{
+		{
+			name:     "links keep their target",
+			document: `<p>Solve <a href="https://example.com/problems/1">Two Sum</a> today</p>`,
+			want:     "Solve Two Sum (https://example.com/problems/1) today\n",
+		},
+		{
+			name:     "anchors and empty links",
+			document: `<p><a href="#top">Top</a> <a>Nowhere</a></p>`,
+			want:     "Top Nowhere\n",
+		},
+		{
+			name:     "lists",
+			document: `<p>Today:</p><ul><li>Two Sum</li><li>Add Two Numbers</li></ul>`,
+			want:     "Today:\n\n- Two Sum\n\n- Add Two Numbers\n",
+		},
+		{
+			name:     "blocks spaced by one blank line",
+			document: `<h1>Hi Ada</h1><div><div><p>First</p></div></div><p>Second<br>line</p>`,
+			want:     "Hi Ada\n\nFirst\n\nSecond\nline\n",
+		},
+		{
+			name:     "whitespace collapsed",
+			document: "<p>  Two\n   Sum\t</p>",
+			want:     "Two Sum\n",
+		},
+		{
+			name:     "table cells",
+			document: `<table><tr><td>1</td><td>Two Sum</td></tr><tr><td>2</td><td>Add Two Numbers</td></tr></table>`,
+			want:     "1 Two Sum\n\n2 Add Two Numbers\n",
+		},
+		{
+			name: "head, style and script skipped",
+			document: `<html><head><title>Digest</title><style>p { color: red }</style></head>` +
+				`<body><script>track()</script><p>Hi</p></body></html>`,
+			want: "Hi\n",
+		},
+		{
+			name:     "hidden preheader skipped",
+			document: `<div style="display: none">3 new problems</div><p>Hi</p>`,
+			want:     "Hi\n",
+		},
+		{
+			name:     "images by their alt text",
+			document: `<p><img src="logo.png" alt="Emailer"><img src="pixel.gif"></p>`,
+			want:     "[Emailer]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromHTML(tt.document)
+			if err != nil {
+				t.Fatalf("FromHTML() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FromHTML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
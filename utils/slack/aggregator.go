@@ -0,0 +1,226 @@
+package slack
+
+import (
+	// Go Internal Packages
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	// Local Packages
+	errors "github.com/satya-ajayy/Emailer/errors"
+	models "github.com/satya-ajayy/Emailer/models"
+
+	// External Packages
+	"go.uber.org/zap"
+)
+
+// maxSampleRecipients caps the recipients listed in a summary.
+const maxSampleRecipients = 5
+
+var (
+	emailRegex  = regexp.MustCompile(`[^\s<>"'@]+@[^\s<>"'@]+\.[A-Za-z]{2,}`)
+	quotedRegex = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+	numberRegex = regexp.MustCompile(`\d+`)
+)
+
+// incident tracks the failures sharing an error signature.
+type incident struct {
+	signature  string
+	first      models.FailedRecord
+	pending    int
+	total      int
+	recipients []string
+	// opened is set once the first summary was posted, threadTS stays empty
+	// when posting through a webhook
+	opened   bool
+	threadTS string
+}
+
+// Aggregator is a Sender that groups failed records by error signature and
+// posts one summary per signature and window instead of one message per
+// record. Later windows reply in the summary's thread (or post unthreaded
+// through a webhook), and once a signature has no failures for a whole window
+// a "recovered" message is posted.
+type Aggregator struct {
+	sender    *SlackSender
+	logger    *zap.Logger
+	window    time.Duration
+	mu        sync.Mutex
+	incidents map[string]*incident
+}
+
+// NewAggregator creates a new Aggregator posting through the sender
+// (PS: Must call Run to start posting the summaries)
+func NewAggregator(sender *SlackSender, logger *zap.Logger, window time.Duration) *Aggregator {
+	return &Aggregator{
+		sender:    sender,
+		logger:    logger,
+		window:    window,
+		incidents: make(map[string]*incident),
+	}
+}
+
+// SendAlert records the failure, which is posted with the next summary.
+func (a *Aggregator) SendAlert(failed models.FailedRecord) error {
+	signature := Signature(failed.Err)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	inc, ok := a.incidents[signature]
+	if !ok {
+		inc = &incident{signature: signature, first: failed}
+		a.incidents[signature] = inc
+	}
+	inc.pending++
+	inc.total++
+
+	if recipient := recipientOf(failed.Record); recipient != "" && len(inc.recipients) < maxSampleRecipients {
+		for _, r := range inc.recipients {
+			if r == recipient {
+				return nil
+			}
+		}
+		inc.recipients = append(inc.recipients, recipient)
+	}
+	return nil
+}
+
+// SendNotice posts the notice right away, notices aren't aggregated.
+func (a *Aggregator) SendNotice(title, message string) error {
+	return a.sender.SendNotice(title, message)
+}
+
+// Run posts the summaries at the end of every window until the context is
+// canceled, then flushes the pending ones.
+func (a *Aggregator) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			a.Flush()
+			return
+		case <-ticker.C:
+			a.Flush()
+		}
+	}
+}
+
+// Flush posts a summary for every signature with failures in the current
+// window and a recovery message for the ones without.
+func (a *Aggregator) Flush() {
+	a.mu.Lock()
+	incidents := make([]*incident, 0, len(a.incidents))
+	for _, inc := range a.incidents {
+		incidents = append(incidents, inc)
+	}
+	a.mu.Unlock()
+
+	sort.Slice(incidents, func(i, j int) bool { return incidents[i].signature < incidents[j].signature })
+	for _, inc := range incidents {
+		a.mu.Lock()
+		pending, total, recipients := inc.pending, inc.total, append([]string(nil), inc.recipients...)
+		opened, threadTS := inc.opened, inc.threadTS
+		inc.pending = 0
+		inc.recipients = nil
+		a.mu.Unlock()
+
+		var err error
+		switch {
+		case !opened:
+			// first window of the incident, open the thread with the details
+			// of its first record
+			text := fmt.Sprintf("*%d* records failed in the last %s%s",
+				pending, a.window, a.formatRecipients(recipients))
+			blocks := append([]Block{{Type: "section", Text: &Text{Type: "mrkdwn", Text: text}}}, a.sender.alertBlocks(inc.first)...)
+			threadTS, err = a.sender.PostBlocks("Error In Emailer", blocks, "")
+			a.mu.Lock()
+			if err == nil {
+				inc.opened, inc.threadTS = true, threadTS
+			} else {
+				// keep the failures pending, the summary is posted again with
+				// the next window's
+				inc.pending += pending
+				inc.recipients = mergeRecipients(recipients, inc.recipients)
+			}
+			a.mu.Unlock()
+		case pending > 0:
+			// ongoing incident, follow up in the thread, if any
+			text := fmt.Sprintf("*%d* more failures in the last %s (%d in total)%s",
+				pending, a.window, total, a.formatRecipients(recipients))
+			_, err = a.sender.Post("Still Failing", text, threadTS)
+			if err != nil {
+				// counted again in the next window's follow-up
+				a.mu.Lock()
+				inc.pending += pending
+				inc.recipients = mergeRecipients(recipients, inc.recipients)
+				a.mu.Unlock()
+			}
+		default:
+			a.mu.Lock()
+			delete(a.incidents, inc.signature)
+			a.mu.Unlock()
+			text := fmt.Sprintf("No failures in the last %s after %d in total\n```%s```",
+				a.window, total, a.sender.redactor.Text(inc.first.Err.Error()))
+			_, err = a.sender.Post("Recovered", text, threadTS)
+		}
+
+		if err != nil {
+			a.logger.Error("failed to send slack message", zap.Error(err))
+		}
+	}
+}
+
+// mergeRecipients appends the recipients that aren't sampled yet, up to
+// maxSampleRecipients.
+func mergeRecipients(sampled, recipients []string) []string {
+	for _, recipient := range recipients {
+		if len(sampled) < maxSampleRecipients && !slices.Contains(sampled, recipient) {
+			sampled = append(sampled, recipient)
+		}
+	}
+	return sampled
+}
+
+// Signature normalizes an error message into a grouping key by masking the
+// parts that vary between records: addresses, quoted values and numbers.
+// Application errors render as JSON, where every value is quoted, so they're
+// grouped by their kind, message and wrapped error instead.
+func Signature(err error) string {
+	msg := err.Error()
+	var appErr *errors.Error
+	if errors.As(err, &appErr) {
+		msg = appErr.Kind.String() + ": " + appErr.Message
+		if appErr.WrappedErr != nil {
+			msg += ": " + appErr.WrappedErr.Error()
+		}
+	}
+
+	msg = emailRegex.ReplaceAllString(msg, "<email>")
+	msg = quotedRegex.ReplaceAllString(msg, "<value>")
+	return numberRegex.ReplaceAllString(msg, "<n>")
+}
+
+// recipientOf returns the recipient of the record, if it can be decoded.
+func recipientOf(record models.Record) string {
+	userLinks, _ := record.UserLinks()
+	return userLinks.User.MailID
+}
+
+func (a *Aggregator) formatRecipients(recipients []string) string {
+	if len(recipients) == 0 {
+		return ""
+	}
+	masked := make([]string, len(recipients))
+	for i, recipient := range recipients {
+		masked[i] = a.sender.redactor.Address(recipient)
+	}
+	return "\nSample recipients: " + strings.Join(masked, ", ")
+}
@@ -0,0 +1,120 @@
+package slack
+
+import (
+	// Go Internal Packages
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	// Local Packages
+	config "github.com/satya-ajayy/Emailer/config"
+	models "github.com/satya-ajayy/Emailer/models"
+
+	// External Packages
+	"go.uber.org/zap"
+)
+
+// webhook records the payloads posted to it, after failing the first
+// failures requests.
+type webhook struct {
+	mu       sync.Mutex
+	failures int
+	payloads []Payload
+}
+
+func (h *webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.failures > 0 {
+		h.failures--
+		http.Error(w, "service_unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	var payload Payload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.payloads = append(h.payloads, payload)
+}
+
+// posted returns the payloads posted since the last call.
+func (h *webhook) posted() []Payload {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	payloads := h.payloads
+	h.payloads = nil
+	return payloads
+}
+
+func failedRecord(offset int64, to string) models.FailedRecord {
+	return models.FailedRecord{
+		Record:   models.Record{Topic: "emails", Offset: offset, Value: []byte(`{"user":{"mail_id":"` + to + `"}}`)},
+		Err:      errors.New("could not send email: 421 service not available"),
+		Attempts: 3,
+	}
+}
+
+func TestAggregatorWebhook(t *testing.T) {
+	hook := &webhook{}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	sender := NewSender(config.Slack{WebhookURL: config.Secret(server.URL)}, true)
+	aggregator := NewAggregator(sender, zap.NewNop(), time.Minute)
+
+	steps := []struct {
+		name     string
+		failures int
+		failed   []models.FailedRecord
+		want     []string
+		summary  string
+	}{
+		{name: "summary fails", failures: 1, failed: []models.FailedRecord{failedRecord(1, "ada@example.com")}},
+		{name: "summary retried", failed: []models.FailedRecord{failedRecord(2, "bob@example.com")},
+			want: []string{"Error In Emailer"}, summary: "*2* records failed"},
+		{name: "follow-up unthreaded", failed: []models.FailedRecord{failedRecord(3, "cy@example.com")},
+			want: []string{"Still Failing"}, summary: "*1* more failures in the last 1m0s (3 in total)"},
+		{name: "follow-up fails", failures: 1, failed: []models.FailedRecord{failedRecord(4, "dee@example.com")}},
+		{name: "follow-up retried", failed: []models.FailedRecord{failedRecord(5, "eve@example.com")},
+			want:    []string{"Still Failing"},
+			summary: "(5 in total)\nSample recipients: dee@example.com, eve@example.com"},
+		{name: "recovered", want: []string{"Recovered"}, summary: "after 5 in total"},
+		{name: "closed"},
+	}
+
+	for _, step := range steps {
+		hook.mu.Lock()
+		hook.failures = step.failures
+		hook.mu.Unlock()
+		for _, failed := range step.failed {
+			if err := aggregator.SendAlert(failed); err != nil {
+				t.Fatalf("%s: SendAlert() error = %v", step.name, err)
+			}
+		}
+		aggregator.Flush()
+
+		var got []string
+		var text string
+		for _, payload := range hook.posted() {
+			if payload.ThreadTS != "" {
+				t.Errorf("%s: webhook payload threaded in %s", step.name, payload.ThreadTS)
+			}
+			got = append(got, payload.Text)
+			text = payload.Blocks[1].Text.Text
+		}
+		if strings.Join(got, ",") != strings.Join(step.want, ",") {
+			t.Fatalf("%s: posted %v, want %v", step.name, got, step.want)
+		}
+		if !strings.Contains(text, step.summary) {
+			t.Errorf("%s: posted %q, want it to contain %q", step.name, text, step.summary)
+		}
+	}
+}
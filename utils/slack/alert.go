@@ -5,71 +5,202 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	// Local Packages
-	config "emailer/config"
-	models "emailer/models"
+	config "github.com/satya-ajayy/Emailer/config"
+	errors "github.com/satya-ajayy/Emailer/errors"
+	models "github.com/satya-ajayy/Emailer/models"
 )
 
 type Text struct {
 	Type string `json:"type"`
 	Text string `json:"text"`
 }
+
+// Block is a Block Kit block, sections carry either a text or fields and
+// context blocks carry elements.
 type Block struct {
-	Type string `json:"type"`
-	Text Text   `json:"text"`
+	Type     string `json:"type"`
+	Text     *Text  `json:"text,omitempty"`
+	Fields   []Text `json:"fields,omitempty"`
+	Elements []Text `json:"elements,omitempty"`
 }
 
 type Payload struct {
-	Blocks []Block `json:"blocks"`
+	Channel  string  `json:"channel,omitempty"`
+	ThreadTS string  `json:"thread_ts,omitempty"`
+	Text     string  `json:"text,omitempty"`
+	Blocks   []Block `json:"blocks"`
 }
 
+// postMessageResponse is the part of the chat.postMessage response we use.
+type postMessageResponse struct {
+	OK    bool   `json:"ok"`
+	TS    string `json:"ts"`
+	Error string `json:"error"`
+}
+
+// postMessageURL is the Slack Web API method used when a bot token is set,
+// since incoming webhooks can't reply in threads.
+const postMessageURL = "https://slack.com/api/chat.postMessage"
+
 type SlackSender struct {
 	client *http.Client
-	config config.Slack
-	isProd bool
+	// apiURL is postMessageURL, but in tests
+	apiURL   string
+	config   config.Slack
+	isProd   bool
+	redactor *Redactor
 }
 
 type Sender interface {
-	SendAlert(record models.Record, err error) error
+	SendAlert(failed models.FailedRecord) error
+	SendNotice(title, message string) error
 }
 
 // NewSender creates a new Slack alert sender
-func NewSender(config config.Slack, isProd bool) Sender {
+func NewSender(config config.Slack, isProd bool) *SlackSender {
 	return &SlackSender{
 		client: &http.Client{Timeout: 5 * time.Second},
+		apiURL: postMessageURL,
 		config: config, isProd: isProd,
+		redactor: NewRedactor(config),
 	}
 }
 
-func (s *SlackSender) SendAlert(record models.Record, err error) error {
-	if s.isProd || (!s.isProd && s.config.SendAlertInDev) {
-		header := Block{
-			Type: "header",
-			Text: Text{
-				Type: "plain_text",
-				Text: "Error In Emailer",
-			},
-		}
+// SendAlert sends an alert for the record that failed to be processed
+func (s *SlackSender) SendAlert(failed models.FailedRecord) error {
+	_, err := s.PostBlocks("Error In Emailer", s.alertBlocks(failed), "")
+	return err
+}
+
+// SendNotice sends an operational notice that isn't tied to a record
+func (s *SlackSender) SendNotice(title, message string) error {
+	_, err := s.Post(title, fmt.Sprintf("```%s```", s.redactor.Text(message)), "")
+	return err
+}
 
-		body := Block{
+// alertBlocks describes the failed record: who it was for, which template,
+// where it sits in Kafka, how often it was tried and why it failed, with the
+// admin API call that sends it again.
+func (s *SlackSender) alertBlocks(failed models.FailedRecord) []Block {
+	userLinks, _ := failed.Record.UserLinks()
+
+	recipient := "unknown"
+	if userLinks.User.MailID != "" {
+		recipient = s.redactor.Address(userLinks.User.MailID)
+	}
+	template := "default"
+	if userLinks.Template != "" {
+		template = userLinks.Template
+	}
+
+	record := failed.Record
+	blocks := []Block{
+		{
 			Type: "section",
-			Text: Text{
-				Type: "mrkdwn",
-				Text: fmt.Sprintf("```Failed To Send Mail to %s\nError: %s\n```",
-					record.Topic, err.Error()),
+			Fields: []Text{
+				{Type: "mrkdwn", Text: "*Recipient*\n" + recipient},
+				{Type: "mrkdwn", Text: "*Template*\n" + template},
+				{Type: "mrkdwn", Text: fmt.Sprintf("*Record*\n%s / %d / %d", record.Topic, record.Partition, record.Offset)},
+				{Type: "mrkdwn", Text: fmt.Sprintf("*Attempt*\n%d", failed.Attempts)},
+				{Type: "mrkdwn", Text: "*Error Kind*\n" + errors.KindOf(failed.Err).String()},
 			},
-		}
+		},
+		{
+			Type: "section",
+			Text: &Text{Type: "mrkdwn", Text: fmt.Sprintf("```%s```", s.redactor.Text(failed.Err.Error()))},
+		},
+	}
 
-		payload := Payload{
-			Blocks: []Block{header, body},
-		}
+	if s.config.AdminURL != "" {
+		sendURL := fmt.Sprintf("%s/v1/admin/records/%s/%d/%d/send", strings.TrimSuffix(s.config.AdminURL, "/"),
+			url.PathEscape(record.Topic), record.Partition, record.Offset)
+		blocks = append(blocks, Block{
+			Type: "context",
+			Elements: []Text{{Type: "mrkdwn", Text: fmt.Sprintf(
+				"Send again: `curl -X POST -H \"Authorization: Bearer $ADMIN_TOKEN\" %s`", sendURL)}},
+		})
+	}
+	return blocks
+}
 
+// Post posts a message, as a reply in the thread of threadTS when set. It
+// returns the timestamp identifying the message, which is only known when
+// posting through the Web API with a bot token.
+func (s *SlackSender) Post(title, text, threadTS string) (string, error) {
+	return s.PostBlocks(title, []Block{{Type: "section", Text: &Text{Type: "mrkdwn", Text: text}}}, threadTS)
+}
+
+// PostBlocks posts the blocks under a header with the title, see Post.
+func (s *SlackSender) PostBlocks(title string, blocks []Block, threadTS string) (string, error) {
+	if !s.isProd && !s.config.SendAlertInDev {
+		return "", nil
+	}
+
+	header := Block{
+		Type: "header",
+		Text: &Text{
+			Type: "plain_text",
+			Text: title,
+		},
+	}
+
+	payload := Payload{
+		Text:   title,
+		Blocks: append([]Block{header}, blocks...),
+	}
+
+	if s.config.BotToken == "" {
 		jsonPayload, _ := json.Marshal(payload)
-		_, err := s.client.Post(s.config.WebhookURL, "application/json", bytes.NewReader(jsonPayload))
-		return err
+		resp, err := s.client.Post(s.config.WebhookURL.Value(), "application/json", bytes.NewReader(jsonPayload))
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		return "", checkStatus(resp)
+	}
+
+	payload.Channel = s.config.Channel
+	payload.ThreadTS = threadTS
+	jsonPayload, _ := json.Marshal(payload)
+	req, err := http.NewRequest(http.MethodPost, s.apiURL, bytes.NewReader(jsonPayload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+s.config.BotToken.Value())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err = checkStatus(resp); err != nil {
+		return "", err
+	}
+
+	var res postMessageResponse
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", fmt.Errorf("error decoding slack response: %v", err)
+	}
+	if !res.OK {
+		return "", fmt.Errorf("slack error: %s", res.Error)
+	}
+	return res.TS, nil
+}
+
+// checkStatus turns a non-2xx response into an error carrying the start of
+// the body, where Slack explains the problem (e.g. "invalid_blocks").
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
 	}
-	return nil
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("slack responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
 }
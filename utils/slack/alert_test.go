@@ -0,0 +1,227 @@
+package slack
+
+import (
+	// Go Internal Packages
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	// Local Packages
+	config "github.com/satya-ajayy/Emailer/config"
+	errors "github.com/satya-ajayy/Emailer/errors"
+	models "github.com/satya-ajayy/Emailer/models"
+)
+
+// capture serves the response and records the request it got.
+type capture struct {
+	status   int
+	response string
+	request  *http.Request
+	payload  Payload
+}
+
+func (c *capture) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.request = r
+	if err := json.NewDecoder(r.Body).Decode(&c.payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(c.status)
+	fmt.Fprint(w, c.response)
+}
+
+// newTestSender returns a sender posting to the server, through the webhook
+// or with a bot token when the config has one.
+func newTestSender(conf config.Slack, server *httptest.Server) *SlackSender {
+	conf.WebhookURL = config.Secret(server.URL + "/webhook")
+	sender := NewSender(conf, true)
+	sender.apiURL = server.URL + "/api/chat.postMessage"
+	return sender
+}
+
+func TestSendAlert(t *testing.T) {
+	failed := models.FailedRecord{
+		Record: models.Record{
+			Topic:     "emails to send",
+			Partition: 3,
+			Offset:    1042,
+			Value:     []byte(`{"template":"digest","user":{"mail_id":"ada@example.com"}}`),
+		},
+		Err:      errors.E(errors.Unavailable, "smtp rejected ada@example.com: auth token=s3cr3t"),
+		Attempts: 3,
+	}
+
+	tests := []struct {
+		name   string
+		conf   config.Slack
+		fields []string
+		errors []string
+		curl   string
+	}{
+		{
+			name: "plain",
+			conf: config.Slack{AdminURL: "http://emailer:2529/emailer/"},
+			fields: []string{"*Recipient*\nada@example.com", "*Template*\ndigest", "*Record*\nemails to send / 3 / 1042",
+				"*Attempt*\n3", "*Error Kind*\nservice unavailable"},
+			errors: []string{"smtp rejected ada@example.com: auth token=s3cr3t"},
+			curl: "Send again: `curl -X POST -H \"Authorization: Bearer $ADMIN_TOKEN\" " +
+				"http://emailer:2529/emailer/v1/admin/records/emails%20to%20send/3/1042/send`",
+		},
+		{
+			name: "redacted",
+			conf: config.Slack{RedactPII: true, RedactPatterns: []string{`token=\S+`}},
+			fields: []string{"*Recipient*\na***@example.com", "*Template*\ndigest", "*Record*\nemails to send / 3 / 1042",
+				"*Attempt*\n3", "*Error Kind*\nservice unavailable"},
+			errors: []string{"smtp rejected a***@example.com: auth [redacted]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &capture{status: http.StatusOK, response: "ok"}
+			server := httptest.NewServer(c)
+			defer server.Close()
+
+			if err := newTestSender(tt.conf, server).SendAlert(failed); err != nil {
+				t.Fatalf("SendAlert() error = %v", err)
+			}
+			if c.request.URL.Path != "/webhook" {
+				t.Errorf("posted to %s, want the webhook", c.request.URL.Path)
+			}
+
+			blocks := c.payload.Blocks
+			wantBlocks := 3
+			if tt.curl != "" {
+				wantBlocks++
+			}
+			if len(blocks) != wantBlocks {
+				t.Fatalf("got %d blocks, want %d: %+v", len(blocks), wantBlocks, blocks)
+			}
+			if blocks[0].Type != "header" || blocks[0].Text.Text != "Error In Emailer" {
+				t.Errorf("header = %+v", blocks[0])
+			}
+
+			if len(blocks[1].Fields) != len(tt.fields) {
+				t.Fatalf("fields = %+v, want %q", blocks[1].Fields, tt.fields)
+			}
+			for i, field := range blocks[1].Fields {
+				if field.Type != "mrkdwn" || field.Text != tt.fields[i] {
+					t.Errorf("field %d = %q, want %q", i, field.Text, tt.fields[i])
+				}
+			}
+
+			errorText := blocks[2].Text.Text
+			for _, want := range tt.errors {
+				if !strings.Contains(errorText, want) {
+					t.Errorf("error block %q doesn't contain %q", errorText, want)
+				}
+			}
+			if tt.conf.RedactPII && (strings.Contains(errorText, "ada@") || strings.Contains(errorText, "s3cr3t")) {
+				t.Errorf("error block %q isn't redacted", errorText)
+			}
+
+			if tt.curl != "" {
+				if blocks[3].Type != "context" || len(blocks[3].Elements) != 1 || blocks[3].Elements[0].Text != tt.curl {
+					t.Errorf("context block = %+v, want %q", blocks[3], tt.curl)
+				}
+			}
+		})
+	}
+}
+
+func TestPostBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		conf     config.Slack
+		threadTS string
+		status   int
+		response string
+		path     string
+		ts       string
+		err      string
+	}{
+		{name: "webhook", status: http.StatusOK, response: "ok", path: "/webhook"},
+		{name: "webhook ignores the thread", threadTS: "1700000000.000100", status: http.StatusOK, response: "ok",
+			path: "/webhook"},
+		{name: "webhook error", status: http.StatusBadRequest, response: "invalid_blocks\n", path: "/webhook",
+			err: "slack responded with status 400: invalid_blocks"},
+		{name: "bot token", conf: config.Slack{BotToken: "xoxb-1", Channel: "#alerts"}, status: http.StatusOK,
+			response: `{"ok":true,"ts":"1700000000.000200"}`, path: "/api/chat.postMessage", ts: "1700000000.000200"},
+		{name: "bot token reply", conf: config.Slack{BotToken: "xoxb-1", Channel: "#alerts"},
+			threadTS: "1700000000.000100", status: http.StatusOK, response: `{"ok":true,"ts":"1700000000.000300"}`,
+			path: "/api/chat.postMessage", ts: "1700000000.000300"},
+		{name: "bot token status", conf: config.Slack{BotToken: "xoxb-1", Channel: "#alerts"},
+			status: http.StatusTooManyRequests, response: "ratelimited", path: "/api/chat.postMessage",
+			err: "slack responded with status 429: ratelimited"},
+		{name: "bot token not ok", conf: config.Slack{BotToken: "xoxb-1", Channel: "#alerts"}, status: http.StatusOK,
+			response: `{"ok":false,"error":"channel_not_found"}`, path: "/api/chat.postMessage",
+			err: "slack error: channel_not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &capture{status: tt.status, response: tt.response}
+			server := httptest.NewServer(c)
+			defer server.Close()
+
+			ts, err := newTestSender(tt.conf, server).Post("Still Failing", "*2* more failures", tt.threadTS)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("Post() error = %v, want %q", err, tt.err)
+				}
+			} else if err != nil {
+				t.Fatalf("Post() error = %v", err)
+			}
+			if ts != tt.ts {
+				t.Errorf("Post() = %q, want %q", ts, tt.ts)
+			}
+
+			if c.request.URL.Path != tt.path {
+				t.Errorf("posted to %s, want %s", c.request.URL.Path, tt.path)
+			}
+			if c.payload.Text != "Still Failing" || len(c.payload.Blocks) != 2 ||
+				c.payload.Blocks[1].Text.Text != "*2* more failures" {
+				t.Errorf("payload = %+v", c.payload)
+			}
+			if tt.conf.BotToken == "" {
+				if auth := c.request.Header.Get("Authorization"); auth != "" || c.payload.ThreadTS != "" {
+					t.Errorf("webhook request has authorization %q and thread %q", auth, c.payload.ThreadTS)
+				}
+				return
+			}
+			if auth := c.request.Header.Get("Authorization"); auth != "Bearer xoxb-1" {
+				t.Errorf("authorization = %q", auth)
+			}
+			if c.payload.Channel != "#alerts" || c.payload.ThreadTS != tt.threadTS {
+				t.Errorf("channel = %q and thread = %q, want #alerts and %q", c.payload.Channel, c.payload.ThreadTS,
+					tt.threadTS)
+			}
+		})
+	}
+}
+
+func TestPostBlocksInDev(t *testing.T) {
+	c := &capture{status: http.StatusOK, response: "ok"}
+	server := httptest.NewServer(c)
+	defer server.Close()
+
+	sender := newTestSender(config.Slack{}, server)
+	sender.isProd = false
+	if err := sender.SendNotice("Templates Reloaded", "3 templates"); err != nil {
+		t.Fatalf("SendNotice() error = %v", err)
+	}
+	if c.request != nil {
+		t.Errorf("posted in dev without send_alert_in_dev")
+	}
+
+	sender.config.SendAlertInDev = true
+	if err := sender.SendNotice("Templates Reloaded", "3 templates"); err != nil {
+		t.Fatalf("SendNotice() error = %v", err)
+	}
+	if c.request == nil || c.payload.Blocks[1].Text.Text != "```3 templates```" {
+		t.Errorf("notice payload = %+v", c.payload)
+	}
+}
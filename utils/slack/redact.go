@@ -0,0 +1,56 @@
+package slack
+
+import (
+	// Go Internal Packages
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	// Local Packages
+	config "github.com/satya-ajayy/Emailer/config"
+)
+
+// redacted replaces the values matched by the redact_patterns.
+const redacted = "[redacted]"
+
+// Redactor masks personal data before it's posted to Slack. Addresses are
+// partially masked when redact_pii is set and the redact_patterns always
+// apply, e.g. to hide tokens that end up in error messages.
+type Redactor struct {
+	pii      bool
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor creates a new Redactor, the patterns must have been validated.
+func NewRedactor(conf config.Slack) *Redactor {
+	r := &Redactor{pii: conf.RedactPII}
+	for _, pattern := range conf.RedactPatterns {
+		r.patterns = append(r.patterns, regexp.MustCompile(pattern))
+	}
+	return r
+}
+
+// Address partially masks an email address, e.g. jane@example.com becomes
+// j***@example.com, keeping the domain which is usually enough to triage.
+func (r *Redactor) Address(address string) string {
+	if !r.pii {
+		return address
+	}
+	local, domain, ok := strings.Cut(address, "@")
+	if !ok || local == "" {
+		return "***"
+	}
+	first, _ := utf8.DecodeRuneInString(local)
+	return string(first) + "***@" + domain
+}
+
+// Text masks the addresses and the values matching the patterns in s.
+func (r *Redactor) Text(s string) string {
+	if r.pii {
+		s = emailRegex.ReplaceAllStringFunc(s, r.Address)
+	}
+	for _, pattern := range r.patterns {
+		s = pattern.ReplaceAllString(s, redacted)
+	}
+	return s
+}